@@ -1,11 +1,19 @@
 package godaikin
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Appliance represents a Daikin HVAC appliance
@@ -47,6 +55,7 @@ type BaseAppliance struct {
 	HTTPClient *http.Client
 	Headers    map[string]string
 	Logger     Logger
+	Tracer     trace.Tracer
 
 	// Translations for converting between Daikin values and human-readable values
 	Translations map[string]map[string]string
@@ -55,21 +64,41 @@ type BaseAppliance struct {
 	InfoResources []string
 
 	MaxConcurrentRequests int
+
+	// MaxResponseSize is the largest response body accepted, in bytes
+	MaxResponseSize int64
+
+	// ClockLocation is the time zone of the unit's clock, time.UTC if nil
+	ClockLocation *time.Location
+
+	// DisableClockSync stops Init from asking the unit to set its clock
+	DisableClockSync bool
+
+	deviceType string
+
+	// health tracks the outcome of recent requests
+	health *healthTracker
+	// metrics is the health gauge callback registered by WithMetrics
+	metrics metric.Registration
+
+	// prepareRequest, if set, adjusts every request before it is sent
+	prepareRequest func(ctx context.Context, req *http.Request) error
 }
 
 func NewBaseAppliance(deviceIP string, logger Logger) *BaseAppliance {
-	if logger == nil {
-		logger = NoOpLogger{}
-	}
 	return &BaseAppliance{
 		DeviceIP:              deviceIP,
 		BaseURL:               fmt.Sprintf("http://%s", deviceIP),
 		Values:                NewValues(),
 		HTTPClient:            &http.Client{Timeout: 30 * time.Second},
 		Headers:               make(map[string]string),
-		Logger:                logger,
+		Logger:                NewRedactingLogger(logger),
+		Tracer:                newTracer(nil),
 		Translations:          make(map[string]map[string]string),
 		MaxConcurrentRequests: 4,
+		MaxResponseSize:       DefaultMaxResponseSize,
+		deviceType:            "BaseAppliance",
+		health:                newHealthTracker(),
 	}
 }
 
@@ -105,10 +134,9 @@ func (b *BaseAppliance) GetTargetTemperature() (float64, error) {
 }
 
 func (b *BaseAppliance) GetMode() string {
-	// TODO remove if don't need power (atleast true for airbase)
-	// if pow, exists := b.Values.Get("pow"); exists && pow == "0" {
-	// 	return "off"
-	// }
+	if pow, exists := b.Values.Get("pow"); exists && pow == "0" {
+		return "off"
+	}
 
 	if mode, exists := b.Values.Get("mode"); exists {
 		return b.translateValue("mode", mode)
@@ -202,27 +230,58 @@ func formatMAC(mac string) string {
 	return result
 }
 
-func (b *BaseAppliance) getResource(ctx context.Context, path string, params map[string]string) (map[string]string, error) {
-	url := fmt.Sprintf("%s/%s", b.BaseURL, path)
+func (b *BaseAppliance) getResource(ctx context.Context, path string, params map[string]string) (result map[string]string, err error) {
+	ctx, span := b.startResourceSpan(ctx, path)
+	defer func() { endSpan(span, err) }()
 
-	b.Logger.Debug("Making HTTP request", "url", url, "params", params)
-
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	body, err := b.fetchResource(ctx, path, params)
 	if err != nil {
-		b.Logger.Error("Failed to create HTTP request", "url", url, "error", err)
-		return nil, NewConnectionError("failed to create request", err)
+		return nil, err
+	}
+	if body == nil {
+		return make(map[string]string), nil
 	}
 
-	for key, value := range b.Headers {
-		req.Header.Set(key, value)
+	result, ret, err := decodeResponse(string(body))
+	if ret != "" {
+		span.SetAttributes(attrRet.String(ret))
 	}
+	return result, err
+}
 
-	if params != nil {
-		q := req.URL.Query()
-		for key, value := range params {
-			q.Add(key, value)
+// getRawResource fetches a resource and returns its undecoded body, nil if
+// the device does not know the resource
+func (b *BaseAppliance) getRawResource(ctx context.Context, path string, params map[string]string) (body []byte, err error) {
+	ctx, span := b.startResourceSpan(ctx, path)
+	defer func() { endSpan(span, err) }()
+
+	return b.fetchResource(ctx, path, params)
+}
+
+// startResourceSpan starts the span around a resource request
+func (b *BaseAppliance) startResourceSpan(ctx context.Context, path string) (context.Context, trace.Span) {
+	resourcePath, _, _ := strings.Cut(path, "?")
+	return b.startSpan(ctx, "daikin.get_resource", attrResourcePath.String(resourcePath))
+}
+
+// fetchResource performs a GET request and reads the whole body, up to
+// MaxResponseSize. It returns a nil body on 404.
+func (b *BaseAppliance) fetchResource(ctx context.Context, path string, params map[string]string) (body []byte, err error) {
+	span := trace.SpanFromContext(ctx)
+	start := time.Now()
+	defer func() { b.health.record(time.Since(start), err) }()
+	url := fmt.Sprintf("%s/%s", b.BaseURL, path)
+
+	b.Logger.Debug("Making HTTP request", "url", url, "params", params)
+
+	req, err := b.newRequest(ctx, "GET", path, params, nil)
+	if err != nil {
+		b.Logger.Error("Failed to create HTTP request", "url", url, "error", err)
+		var authErr *AuthenticationError
+		if errors.As(err, &authErr) {
+			return nil, err
 		}
-		req.URL.RawQuery = q.Encode()
+		return nil, NewConnectionError("failed to create request", err)
 	}
 
 	resp, err := b.HTTPClient.Do(req)
@@ -232,6 +291,8 @@ func (b *BaseAppliance) getResource(ctx context.Context, path string, params map
 	}
 	defer resp.Body.Close()
 
+	span.SetAttributes(attrHTTPStatus.Int(resp.StatusCode))
+
 	if resp.StatusCode == http.StatusForbidden {
 		b.Logger.Warn("HTTP 403 Forbidden response", "url", url)
 		return nil, NewAuthenticationError("HTTP 403 Forbidden", nil)
@@ -239,7 +300,7 @@ func (b *BaseAppliance) getResource(ctx context.Context, path string, params map
 
 	if resp.StatusCode == http.StatusNotFound {
 		b.Logger.Debug("HTTP 404 Not Found response", "url", url)
-		return make(map[string]string), nil
+		return nil, nil
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -247,15 +308,110 @@ func (b *BaseAppliance) getResource(ctx context.Context, path string, params map
 		return nil, NewConnectionError(fmt.Sprintf("unexpected HTTP status: %d", resp.StatusCode), nil)
 	}
 
-	body := make([]byte, 4096) // Reasonable buffer size for Daikin responses
-	n, err := resp.Body.Read(body)
-	if err != nil && n == 0 {
+	body, err = readBody(resp.Body, b.MaxResponseSize)
+	if err != nil {
 		b.Logger.Error("Failed to read response body", "url", url, "error", err)
-		return nil, NewConnectionError("failed to read response body", err)
+		return nil, err
 	}
 
-	b.Logger.Debug("HTTP response received", "url", url, "bytes", n, "status", resp.StatusCode)
-	return parseResponse(string(body[:n]))
+	b.Logger.Debug("HTTP response received", "url", url, "bytes", len(body), "status", resp.StatusCode)
+	return body, nil
+}
+
+// newRequest builds a request for a resource below BaseURL with the
+// appliance headers and the given query parameters and body
+func (b *BaseAppliance) newRequest(ctx context.Context, method, path string, params map[string]string, body []byte) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("%s/%s", b.BaseURL, path), reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range b.Headers {
+		req.Header.Set(key, value)
+	}
+
+	if params != nil {
+		q := req.URL.Query()
+		for key, value := range params {
+			q.Add(key, value)
+		}
+		req.URL.RawQuery = q.Encode()
+	}
+
+	if b.prepareRequest != nil {
+		if err := b.prepareRequest(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+
+	return req, nil
+}
+
+// rawQuery appends params, already encoded, to path in key order. The
+// adapters expect values such as names and zone lists to be encoded once,
+// which newRequest would encode again.
+func rawQuery(path string, params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key + "=" + params[key]
+	}
+	return path + "?" + strings.Join(parts, "&")
+}
+
+// command requests a resource that changes the adapter, failing if the
+// adapter does not answer ret=OK, which getResource reports as no values
+func (b *BaseAppliance) command(ctx context.Context, path string) error {
+	return b.commandParams(ctx, path, nil)
+}
+
+// commandParams is command with query parameters, encoded as by getResource
+func (b *BaseAppliance) commandParams(ctx context.Context, path string, params map[string]string) (err error) {
+	ctx, span := b.startResourceSpan(ctx, path)
+	defer func() { endSpan(span, err) }()
+
+	body, err := b.fetchResource(ctx, path, params)
+	if err != nil {
+		return err
+	}
+	_, ret, err := decodeResponse(string(body))
+	if ret != "" {
+		span.SetAttributes(attrRet.String(ret))
+	}
+	if err != nil {
+		return err
+	}
+	if ret != "OK" {
+		return NewDaikinError(fmt.Sprintf("request rejected: ret=%s", ret), nil)
+	}
+	return nil
+}
+
+// setName renames the adapter through its set_name resource
+func (b *BaseAppliance) setName(ctx context.Context, path, name string) error {
+	if name == "" {
+		return fmt.Errorf("name cannot be empty")
+	}
+
+	b.Logger.Info("Setting device name", "name", name)
+	if err := b.command(ctx, rawQuery(path, map[string]string{"name": encodeValue(name)})); err != nil {
+		return fmt.Errorf("failed to set name: %w", err)
+	}
+	b.Values.Set("name", name)
+	return nil
 }
 
 func (b *BaseAppliance) Init(ctx context.Context) error {
@@ -3,6 +3,7 @@ package godaikin
 import (
 	"context"
 	"fmt"
+	"math"
 	"strconv"
 	"time"
 )
@@ -95,6 +96,7 @@ func NewDaikinBRP069(deviceIP string, logger Logger) *DaikinBRP069 {
 
 	// BRP069 only allows 1 concurrent request
 	base.MaxConcurrentRequests = 1
+	base.deviceType = "BRP069"
 
 	return &DaikinBRP069{BaseAppliance: base}
 }
@@ -105,9 +107,16 @@ func (d *DaikinBRP069) GetDeviceType() string {
 
 // Init initializes the BRP069 device
 func (d *DaikinBRP069) Init(ctx context.Context) error {
-	// Auto-set clock first
-	if err := d.autoSetClock(ctx); err != nil {
-		d.Logger.Warn("Failed to auto-set clock", "error", err)
+	// Auto-set clock first. A unit kept in a configured location is set in
+	// it, so that readings match.
+	if !d.DisableClockSync {
+		syncClock := d.autoSetClock
+		if d.ClockLocation != nil {
+			syncClock = func(ctx context.Context) error { return d.SetClock(ctx, time.Now(), nil) }
+		}
+		if err := syncClock(ctx); err != nil {
+			d.Logger.Warn("Failed to auto-set clock", "error", err)
+		}
 	}
 
 	// Update status with basic info first
@@ -124,7 +133,10 @@ func (d *DaikinBRP069) Init(ctx context.Context) error {
 }
 
 // UpdateStatus updates the device status using info resources
-func (d *DaikinBRP069) UpdateStatus(ctx context.Context) error {
+func (d *DaikinBRP069) UpdateStatus(ctx context.Context) (err error) {
+	ctx, span := d.startSpan(ctx, "daikin.update_status")
+	defer func() { endSpan(span, err) }()
+
 	resources := d.InfoResources
 
 	// Add energy resources if supported
@@ -190,7 +202,10 @@ func (d *DaikinBRP069) parseSpecialFields(data map[string]string) map[string]str
 }
 
 // Set sets device parameters
-func (d *DaikinBRP069) Set(ctx context.Context, settings map[string]string) error {
+func (d *DaikinBRP069) Set(ctx context.Context, settings map[string]string) (err error) {
+	ctx, span := d.startSpan(ctx, "daikin.set")
+	defer func() { endSpan(span, err) }()
+
 	// Update settings first
 	if err := d.updateSettings(ctx, settings); err != nil {
 		return fmt.Errorf("failed to update settings: %w", err)
@@ -235,7 +250,7 @@ func (d *DaikinBRP069) Set(ctx context.Context, settings map[string]string) erro
 	d.Logger.Info("Setting device parameters", "params", params)
 
 	// Make the request
-	_, err := d.getResource(ctx, "aircon/set_control_info", params)
+	_, err = d.getResource(ctx, "aircon/set_control_info", params)
 	if err != nil {
 		return fmt.Errorf("failed to set control info: %w", err)
 	}
@@ -370,21 +385,14 @@ func (d *DaikinBRP069) autoSetClock(ctx context.Context) error {
 	return err
 }
 
-// SetClock sets the clock on the AC to the current time
-func (d *DaikinBRP069) SetClock(ctx context.Context) error {
-	now := time.Now().UTC()
-	params := map[string]string{
-		"date": now.Format("2006/01/02"),
-		"zone": "GMT",
-		"time": now.Format("15:04:05"),
-	}
-
-	_, err := d.getResource(ctx, "common/notify_date_time", params)
-	if err != nil {
-		return fmt.Errorf("failed to set clock: %w", err)
-	}
+// GetClock returns the time on the unit's clock
+func (d *DaikinBRP069) GetClock(ctx context.Context) (time.Time, error) {
+	return d.getClock(ctx, "common/get_datetime")
+}
 
-	return nil
+// SetClock sets the clock on the AC
+func (d *DaikinBRP069) SetClock(ctx context.Context, t time.Time, location *time.Location) error {
+	return d.setClock(ctx, "common/notify_date_time", t, location)
 }
 
 // SupportsHumidity returns whether the device has humidity sensor
@@ -400,11 +408,502 @@ func (d *DaikinBRP069) GetHumidity() (float64, error) {
 	return d.parseFloat("hhum")
 }
 
+// SupportsHumidityControl returns whether the unit has a humidity setpoint,
+// which units without one report as 0 for every mode
+func (d *DaikinBRP069) SupportsHumidityControl() bool {
+	for _, key := range []string{"shum", "dh1", "dh2", "dh3", "dh4", "dh5", "dh7"} {
+		if value, exists := d.Values.GetWithInvalidation(key, false); exists && !isUnsetHumidity(value) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetTargetHumidity returns the target humidity
 func (d *DaikinBRP069) GetTargetHumidity() (float64, error) {
 	return d.parseFloat("shum")
 }
 
+// GetHumidityTarget returns the target humidity, including the special
+// targets of dry mode that GetTargetHumidity cannot express
+func (d *DaikinBRP069) GetHumidityTarget() (HumidityTarget, error) {
+	if value, exists := d.Values.Get("shum"); exists && !isUnsetHumidity(value) {
+		return HumidityTarget(value), nil
+	}
+	return "", fmt.Errorf("no target humidity")
+}
+
+// SetTargetHumidity sets the target humidity of the current mode, leaving
+// the power state as it is. Units without a humidity setpoint return a
+// CapabilityError.
+func (d *DaikinBRP069) SetTargetHumidity(ctx context.Context, target HumidityTarget) error {
+	if !d.SupportsHumidityControl() {
+		return NewCapabilityError("humidity control not supported by this unit", nil)
+	}
+	if err := target.validate(); err != nil {
+		return err
+	}
+	if err := d.setModeControl(ctx, "", map[string]string{"shum": string(target)}); err != nil {
+		return fmt.Errorf("failed to set target humidity: %w", err)
+	}
+	return nil
+}
+
+// brp069SetpointModes maps the mode names setpoint memory and louver
+// settings share with BRP084 to the BRP069 mode codes. Auto is reported as
+// 1, 0 or 7 depending on the unit; dt5 belongs to no known mode.
+var brp069SetpointModes = map[string][]string{
+	"auto": {"1", "0", "7"},
+	"dry":  {"2"},
+	"cool": {"3"},
+	"heat": {"4"},
+	"fan":  {"6"},
+}
+
+// modeCode returns the code of a mode given by its normalized name, or by
+// its translation such as "hot". Of the auto codes it picks the current
+// mode, then the first one with setpoint memory.
+func (d *DaikinBRP069) modeCode(mode string, values map[string]string) string {
+	codes, exists := brp069SetpointModes[mode]
+	if !exists {
+		return d.reverseTranslateValue("mode", mode)
+	}
+	for _, code := range codes {
+		if code == values["mode"] {
+			return code
+		}
+	}
+	for _, code := range codes {
+		if _, exists := values["dt"+code]; exists {
+			return code
+		}
+	}
+	return codes[0]
+}
+
+// GetModeSetpoints returns the setpoints remembered for each mode
+// (dt<n>, dh<n> and dfr<n> in get_control_info), keyed by the mode names
+// BRP084 uses: auto, dry, cool, heat and fan
+func (d *DaikinBRP069) GetModeSetpoints() map[string]ModeSetpoint {
+	values := d.Values.All()
+	setpoints := make(map[string]ModeSetpoint)
+	for mode := range brp069SetpointModes {
+		code := d.modeCode(mode, values)
+		temperature, hasTemperature := values["dt"+code]
+		humidity, hasHumidity := values["dh"+code]
+		fanRate, hasFanRate := values["dfr"+code]
+		if !hasTemperature && !hasHumidity && !hasFanRate {
+			continue
+		}
+
+		setpoint := ModeSetpoint{Temperature: temperature, Humidity: HumidityTarget(humidity)}
+		if hasFanRate {
+			setpoint.FanRate = d.translateValue("f_rate", fanRate)
+		}
+		setpoints[mode] = setpoint
+	}
+	return setpoints
+}
+
+// SetModeSetpoint changes the setpoint remembered for a mode, leaving the
+// unit in its current mode
+func (d *DaikinBRP069) SetModeSetpoint(ctx context.Context, mode string, setpoint ModeSetpoint) error {
+	if setpoint.Humidity != "" {
+		if err := setpoint.Humidity.validate(); err != nil {
+			return err
+		}
+	}
+
+	values := map[string]string{"stemp": setpoint.Temperature, "shum": string(setpoint.Humidity)}
+	if setpoint.FanRate != "" {
+		values["f_rate"] = d.reverseTranslateValue("f_rate", setpoint.FanRate)
+	}
+
+	if err := d.setModeControl(ctx, mode, values); err != nil {
+		return fmt.Errorf("failed to set mode setpoint: %w", err)
+	}
+	return nil
+}
+
+// modeMemoryKeys are the prefixes of the per-mode copies of control values
+var modeMemoryKeys = map[string]string{
+	"stemp":  "dt",
+	"shum":   "dh",
+	"f_rate": "dfr",
+	"f_dir":  "dfd",
+}
+
+// setModeControl changes the values remembered for a mode (dt<n>, dh<n>,
+// dfr<n>, dfd<n>) given by their control key, or for the current mode when
+// mode is empty. The current control state is sent unchanged alongside, so
+// the unit stays in its current mode and power state; when that is the
+// mode being changed the values also apply immediately.
+func (d *DaikinBRP069) setModeControl(ctx context.Context, mode string, values map[string]string) error {
+	current, err := d.getResource(ctx, "aircon/get_control_info", nil)
+	if err != nil {
+		return fmt.Errorf("failed to get current control info: %w", err)
+	}
+	d.Values.UpdateByResource("aircon/get_control_info", d.parseSpecialFields(current))
+
+	code := current["mode"]
+	if mode != "" {
+		code = d.modeCode(mode, current)
+	}
+	_, hasMemory := current["dt"+code]
+	if !hasMemory && code != current["mode"] {
+		return fmt.Errorf("no setpoint memory for mode: %s", mode)
+	}
+
+	params := make(map[string]string)
+	for _, key := range []string{"pow", "mode", "stemp", "shum", "f_rate", "f_dir", "f_dir_ud", "f_dir_lr"} {
+		if value, exists := current[key]; exists {
+			params[key] = value
+		}
+	}
+
+	for key, value := range values {
+		if value == "" {
+			continue
+		}
+		if prefix, exists := modeMemoryKeys[key]; exists && hasMemory {
+			params[prefix+code] = value
+		}
+		if code == current["mode"] {
+			params[key] = value
+		}
+	}
+	// Units with an axis each take f_dir_ud and f_dir_lr instead of f_dir,
+	// as in Set
+	if _, hasUDLR := params["f_dir_lr"]; hasUDLR {
+		delete(params, "f_dir")
+	}
+
+	d.Logger.Info("Setting mode control", "mode", mode, "params", params)
+	if err := d.commandParams(ctx, "aircon/set_control_info", params); err != nil {
+		return err
+	}
+
+	d.Values.Update(params)
+	return nil
+}
+
+// SupportsLouverControl returns whether the louvers can be set, which the
+// unit reports through f_dir_ud/f_dir_lr or en_fdir in its model info
+func (d *DaikinBRP069) SupportsLouverControl() bool {
+	if d.Values.Has("f_dir_ud") || d.Values.Has("f_dir_lr") {
+		return true
+	}
+	enFDir, _ := d.Values.GetWithInvalidation("en_fdir", false)
+	return enFDir == "1"
+}
+
+// GetLouverSettings returns the louver settings of a mode. BRP069 adapters
+// only switch swing on and off per axis, so every other state reads as
+// LouverStopped.
+func (d *DaikinBRP069) GetLouverSettings(mode string) (LouverSettings, error) {
+	if !d.SupportsLouverControl() {
+		return LouverSettings{}, NewCapabilityError("louver control not supported by this unit", nil)
+	}
+
+	values := d.Values.All()
+	if values["f_dir_ud"] != "" || values["f_dir_lr"] != "" {
+		if d.modeCode(mode, values) != values["mode"] {
+			return LouverSettings{}, NewCapabilityError("louver settings are only available for the current mode", nil)
+		}
+		return LouverSettings{
+			Vertical:   swingPosition(values["f_dir_ud"] == "S"),
+			Horizontal: swingPosition(values["f_dir_lr"] == "S"),
+		}, nil
+	}
+
+	code := d.modeCode(mode, values)
+	fDir, exists := values["dfd"+code]
+	if !exists {
+		if code != values["mode"] {
+			return LouverSettings{}, fmt.Errorf("no louver settings for mode: %s", mode)
+		}
+		fDir = values["f_dir"]
+	}
+
+	return LouverSettings{
+		Vertical:   swingPosition(fDir == "1" || fDir == "3"),
+		Horizontal: swingPosition(fDir == "2" || fDir == "3"),
+	}, nil
+}
+
+// SetLouverSettings sets the louver settings of a mode. Only LouverSwing
+// and LouverStopped are supported; empty fields are left unchanged.
+func (d *DaikinBRP069) SetLouverSettings(ctx context.Context, mode string, settings LouverSettings) error {
+	if !d.SupportsLouverControl() {
+		return NewCapabilityError("louver control not supported by this unit", nil)
+	}
+	if settings.Vertical == "" || settings.Horizontal == "" {
+		current, err := d.GetLouverSettings(mode)
+		if err != nil {
+			return err
+		}
+		if settings.Vertical == "" {
+			settings.Vertical = current.Vertical
+		}
+		if settings.Horizontal == "" {
+			settings.Horizontal = current.Horizontal
+		}
+	}
+	for _, position := range []LouverPosition{settings.Vertical, settings.Horizontal} {
+		if position != LouverSwing && position != LouverStopped {
+			return NewCapabilityError(fmt.Sprintf("louver position %s not supported by this unit", position), nil)
+		}
+	}
+
+	// f_dir is 0 for no swing, 1 vertical, 2 horizontal and 3 both
+	fDir := 0
+	if settings.Vertical == LouverSwing {
+		fDir |= 1
+	}
+	if settings.Horizontal == LouverSwing {
+		fDir |= 2
+	}
+
+	if d.Values.Has("f_dir_ud") || d.Values.Has("f_dir_lr") {
+		if values := d.Values.All(); d.modeCode(mode, values) != values["mode"] {
+			return NewCapabilityError("louver settings can only be changed for the current mode", nil)
+		}
+		// These units take an axis each, "S" for swing and "0" for none
+		axes := map[string]string{"f_dir_ud": "0", "f_dir_lr": "0"}
+		if settings.Vertical == LouverSwing {
+			axes["f_dir_ud"] = "S"
+		}
+		if settings.Horizontal == LouverSwing {
+			axes["f_dir_lr"] = "S"
+		}
+		if err := d.setModeControl(ctx, "", axes); err != nil {
+			return fmt.Errorf("failed to set louver settings: %w", err)
+		}
+		d.Values.Set("f_dir", strconv.Itoa(fDir))
+		return nil
+	}
+
+	if err := d.setModeControl(ctx, mode, map[string]string{"f_dir": strconv.Itoa(fDir)}); err != nil {
+		return fmt.Errorf("failed to set louver settings: %w", err)
+	}
+	return nil
+}
+
+func swingPosition(swing bool) LouverPosition {
+	if swing {
+		return LouverSwing
+	}
+	return LouverStopped
+}
+
+// SetName renames the adapter
+func (d *DaikinBRP069) SetName(ctx context.Context, name string) error {
+	return d.setName(ctx, "common/set_name", name)
+}
+
+// SupportsFaults reports whether the unit reported an error code (err in
+// get_sensor_info) in its last status update
+func (d *DaikinBRP069) SupportsFaults() bool {
+	return d.supportsFaults()
+}
+
+// Faults returns the error code of the last status update. Units report it
+// as a number whose mapping to the letter codes of FaultDescriptions is not
+// known, so such faults keep the number as Code and describe as
+// "Unknown fault".
+func (d *DaikinBRP069) Faults() ([]Fault, error) {
+	return d.faults()
+}
+
+// SupportsRemoteSettings returns whether the adapter reported its remote
+// method (common/get_remote_method)
+func (d *DaikinBRP069) SupportsRemoteSettings() bool {
+	return d.Values.Has("method")
+}
+
+// GetRemoteSettings returns the cloud connection setting
+func (d *DaikinBRP069) GetRemoteSettings(ctx context.Context) (*RemoteSettings, error) {
+	data, err := d.getResource(ctx, "common/get_remote_method", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote method: %w", err)
+	}
+	method, exists := data["method"]
+	if !exists {
+		return nil, NewCapabilityError("remote method not reported by this unit", nil)
+	}
+
+	settings := &RemoteSettings{Method: RemoteMethod(decodeValue(method))}
+	if seconds, err := strconv.Atoi(data["notice_ip_int"]); err == nil {
+		settings.NoticeInterval = time.Duration(seconds) * time.Second
+	}
+	if seconds, err := strconv.Atoi(data["notice_sync_int"]); err == nil {
+		settings.SyncInterval = time.Duration(seconds) * time.Second
+	}
+	return settings, nil
+}
+
+// SetRemoteSettings sets the cloud connection; RemoteHomeOnly makes the
+// adapter local-only
+func (d *DaikinBRP069) SetRemoteSettings(ctx context.Context, settings RemoteSettings) error {
+	if err := settings.validate(); err != nil {
+		return err
+	}
+
+	if settings.NoticeInterval == 0 || settings.SyncInterval == 0 {
+		current, err := d.GetRemoteSettings(ctx)
+		if err != nil {
+			return err
+		}
+		if settings.NoticeInterval == 0 {
+			settings.NoticeInterval = current.NoticeInterval
+		}
+		if settings.SyncInterval == 0 {
+			settings.SyncInterval = current.SyncInterval
+		}
+	}
+
+	params := map[string]string{
+		"method":          encodeValue(string(settings.Method)),
+		"notice_ip_int":   strconv.Itoa(int(settings.NoticeInterval / time.Second)),
+		"notice_sync_int": strconv.Itoa(int(settings.SyncInterval / time.Second)),
+	}
+
+	d.Logger.Info("Setting remote method", "method", settings.Method)
+	if err := d.command(ctx, rawQuery("common/set_remote_method", params)); err != nil {
+		return fmt.Errorf("failed to set remote method: %w", err)
+	}
+	d.Values.Set("method", string(settings.Method))
+	return nil
+}
+
+// GetPrice returns the electricity price per kWh configured on the unit:
+// price_int whole units plus price_dec hundredths, from get_price
+func (d *DaikinBRP069) GetPrice() (float64, error) {
+	value, exists := d.Values.Get("price_int")
+	if !exists {
+		return 0, NewCapabilityError("no price configured", nil)
+	}
+	whole, err := strconv.Atoi(value)
+	if err != nil || whole < 0 {
+		return 0, NewParseError(fmt.Sprintf("invalid price_int %q", value), err)
+	}
+
+	hundredths := 0
+	if value, _ := d.Values.Get("price_dec"); value != "" {
+		hundredths, err = strconv.Atoi(value)
+		if err != nil || hundredths < 0 || hundredths > 99 {
+			return 0, NewParseError(fmt.Sprintf("invalid price_dec %q", value), err)
+		}
+	}
+
+	return float64(whole) + float64(hundredths)/100, nil
+}
+
+// SetPrice sets the electricity price per kWh, rounded to hundredths
+func (d *DaikinBRP069) SetPrice(ctx context.Context, price float64) error {
+	if price < 0 || math.IsNaN(price) || math.IsInf(price, 0) {
+		return fmt.Errorf("invalid price: %v", price)
+	}
+
+	hundredths := int64(math.Round(price * 100))
+	params := map[string]string{
+		"price_int": strconv.FormatInt(hundredths/100, 10),
+		"price_dec": fmt.Sprintf("%02d", hundredths%100),
+	}
+
+	d.Logger.Info("Setting price", "price", price)
+	if err := d.command(ctx, rawQuery("aircon/set_price", params)); err != nil {
+		return fmt.Errorf("failed to set price: %w", err)
+	}
+	d.Values.Update(params)
+	return nil
+}
+
+// GetEnergyHistory returns the energy history: hours of today and
+// yesterday (get_day_power_ex, 0.1 kWh), the last 7 days (get_week_power,
+// Wh) and the months of this and last year (get_year_power, 0.1 kWh). It
+// reads the values of the last UpdateStatus and sends no request.
+func (d *DaikinBRP069) GetEnergyHistory(now time.Time) (*EnergyHistory, error) {
+	if !d.SupportsEnergyConsumption() {
+		return nil, NewCapabilityError("energy consumption not reported by this unit", nil)
+	}
+
+	history := &EnergyHistory{}
+	today := startOfDay(now)
+
+	for _, day := range []struct {
+		prefix string
+		start  time.Time
+		hours  int
+	}{
+		{"prev_1day", today.AddDate(0, 0, -1), 24},
+		{"curr_day", today, now.Hour() + 1},
+	} {
+		hourly, err := d.sumEnergyLists(0.1, day.prefix+"_cool", day.prefix+"_heat")
+		if err != nil {
+			return nil, err
+		}
+		for hour, energy := range hourly {
+			if hour < day.hours {
+				start := time.Date(day.start.Year(), day.start.Month(), day.start.Day(), hour, 0, 0, 0, now.Location())
+				history.Hourly = append(history.Hourly, EnergyReading{Start: start, Energy: energy})
+			}
+		}
+	}
+
+	daily, err := d.sumEnergyLists(0.001, "datas")
+	if err != nil {
+		return nil, err
+	}
+	history.Daily = dailyReadings(daily, now)
+
+	for _, year := range []struct {
+		key    string
+		year   int
+		months int
+	}{
+		{"previous_year", now.Year() - 1, 12},
+		{"this_year", now.Year(), int(now.Month())},
+	} {
+		monthly, err := d.sumEnergyLists(0.1, year.key)
+		if err != nil {
+			return nil, err
+		}
+		for month, energy := range monthly {
+			if month < year.months {
+				start := time.Date(year.year, time.Month(month+1), 1, 0, 0, 0, 0, now.Location())
+				history.Monthly = append(history.Monthly, EnergyReading{Start: start, Energy: energy})
+			}
+		}
+	}
+
+	return history, nil
+}
+
+// sumEnergyLists adds up the histories stored under keys, which may be
+// missing
+func (d *DaikinBRP069) sumEnergyLists(scale float64, keys ...string) ([]float64, error) {
+	var sum []float64
+	for _, key := range keys {
+		value, exists := d.Values.GetWithInvalidation(key, false)
+		if !exists {
+			continue
+		}
+		energies, err := parseEnergyList(value, scale)
+		if err != nil {
+			return nil, err
+		}
+		for i, energy := range energies {
+			if i < len(sum) {
+				sum[i] += energy
+			} else {
+				sum = append(sum, energy)
+			}
+		}
+	}
+	return sum, nil
+}
+
 // GetCompressorFrequency returns the current compressor frequency
 func (d *DaikinBRP069) GetCompressorFrequency() (float64, error) {
 	return d.parseFloat("cmpfreq")
@@ -0,0 +1,142 @@
+package godaikin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// DefaultOnlinePollInterval is how often WaitOnline polls a rebooting
+// adapter
+const DefaultOnlinePollInterval = 2 * time.Second
+
+// NotifySettings are the adapter's notification settings
+// (common/get_notify)
+type NotifySettings struct {
+	// AutoOff turns the unit off AutoOffDelay after it was switched on
+	AutoOff      bool
+	AutoOffDelay time.Duration
+}
+
+// Reboot restarts the adapter. The unit keeps running; the adapter is
+// unreachable until it is back on the network, see WaitOnline.
+func (d *DaikinBRP069) Reboot(ctx context.Context) error {
+	d.Logger.Info("Rebooting adapter")
+
+	// The adapter may drop the connection as it reboots
+	if err := d.command(ctx, "common/reboot"); err != nil {
+		var connectionErr *ConnectionError
+		if !errors.As(err, &connectionErr) {
+			return fmt.Errorf("failed to reboot adapter: %w", err)
+		}
+	}
+	return nil
+}
+
+// WaitOnline waits for the adapter to go down after a reboot and answer
+// again, polling every interval (DefaultOnlinePollInterval if zero) until
+// ctx is done. The adapter reports no uptime, so a reboot is only noticed
+// by a failed request; the interval must be shorter than the reboot.
+func (d *DaikinBRP069) WaitOnline(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		interval = DefaultOnlinePollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	down := false
+	for {
+		select {
+		case <-ctx.Done():
+			if !down {
+				return NewConnectionError("adapter did not go down after reboot", ctx.Err())
+			}
+			return NewConnectionError("adapter did not come back online", ctx.Err())
+		case <-ticker.C:
+		}
+
+		_, err := d.getRawResource(ctx, "common/basic_info", nil)
+		switch {
+		case ctx.Err() != nil:
+			// Reported at the top of the loop
+		case err != nil && !down:
+			d.Logger.Debug("Adapter went down", "error", err)
+			down = true
+		case err == nil && down:
+			d.Logger.Info("Adapter back online")
+			return nil
+		}
+	}
+}
+
+// SupportsLED returns whether the adapter reports its LED (led in
+// basic_info)
+func (d *DaikinBRP069) SupportsLED() bool {
+	return d.Values.Has("led")
+}
+
+// GetLED returns whether the adapter's indicator LED is on
+func (d *DaikinBRP069) GetLED() (bool, error) {
+	led, exists := d.Values.Get("led")
+	if !exists {
+		return false, NewCapabilityError("LED not reported by this adapter", nil)
+	}
+	return led == "1", nil
+}
+
+// SetLED turns the adapter's indicator LED on or off
+func (d *DaikinBRP069) SetLED(ctx context.Context, on bool) error {
+	if !d.SupportsLED() {
+		return NewCapabilityError("LED not reported by this adapter", nil)
+	}
+
+	d.Logger.Info("Setting LED", "on", on)
+	if err := d.command(ctx, rawQuery("common/set_led", map[string]string{"led": boolParam(on)})); err != nil {
+		return fmt.Errorf("failed to set LED: %w", err)
+	}
+	d.Values.Set("led", boolParam(on))
+	return nil
+}
+
+// GetNotifySettings returns the notification settings read by Init
+func (d *DaikinBRP069) GetNotifySettings() (NotifySettings, error) {
+	flag, exists := d.Values.GetWithInvalidation("auto_off_flg", false)
+	if !exists {
+		return NotifySettings{}, NewCapabilityError("notification settings not reported by this adapter", nil)
+	}
+
+	settings := NotifySettings{AutoOff: flag == "1"}
+	delay, _ := d.Values.GetWithInvalidation("auto_off_tm", false)
+	if minutes, err := strconv.Atoi(delay); err == nil {
+		settings.AutoOffDelay = time.Duration(minutes) * time.Minute
+	}
+	return settings, nil
+}
+
+// SetNotifySettings sets the notification settings. The delay is whole
+// minutes and required when AutoOff is set.
+func (d *DaikinBRP069) SetNotifySettings(ctx context.Context, settings NotifySettings) error {
+	if settings.AutoOffDelay < 0 || settings.AutoOffDelay%time.Minute != 0 {
+		return fmt.Errorf("invalid auto-off delay: %v", settings.AutoOffDelay)
+	}
+	if settings.AutoOff && settings.AutoOffDelay == 0 {
+		return errors.New("auto-off needs a delay")
+	}
+
+	params := map[string]string{
+		"auto_off_flg": boolParam(settings.AutoOff),
+		"auto_off_tm":  "-",
+	}
+	if settings.AutoOffDelay > 0 {
+		params["auto_off_tm"] = strconv.Itoa(int(settings.AutoOffDelay / time.Minute))
+	}
+
+	d.Logger.Info("Setting notifications", "auto_off", settings.AutoOff, "delay", settings.AutoOffDelay)
+	if err := d.command(ctx, rawQuery("common/set_notify", params)); err != nil {
+		return fmt.Errorf("failed to set notifications: %w", err)
+	}
+	d.Values.Update(params)
+	return nil
+}
@@ -0,0 +1,386 @@
+package godaikin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultScheduleDetail is the entry layout of firmwares that do not send
+// f_detail: field name and width in characters, in order
+const defaultScheduleDetail = "total#18;_en#1;_pow#1;_mode#1;_temp#4;_time#4;_vol#1;_dir#1;_humi#3;_spmd#2"
+
+// scheduleDays are the keys of the weekly programme, one per weekday
+var scheduleDays = map[time.Weekday]string{
+	time.Monday:    "moc",
+	time.Tuesday:   "tuc",
+	time.Wednesday: "wec",
+	time.Thursday:  "thc",
+	time.Friday:    "frc",
+	time.Saturday:  "sac",
+	time.Sunday:    "suc",
+}
+
+// TimerEntry is one programmed action of a timer or weekly schedule
+type TimerEntry struct {
+	Enabled bool
+	// Hour and Minute are the local time the entry fires
+	Hour   int
+	Minute int
+	Power  bool
+	// Mode, FanRate and FanDirection use the same values as Set
+	Mode         string
+	Temperature  string
+	FanRate      string
+	FanDirection string
+	Humidity     string
+	SpecialMode  string
+}
+
+// ScheduleInfo describes the weekly schedules stored on the unit
+type ScheduleInfo struct {
+	Enabled bool
+	// Active is the number of the schedule in use, from 1
+	Active int
+	Count  int
+	PerDay int
+	Names  []string
+
+	format recordFormat
+}
+
+// Schedule is a weekly programme, up to ScheduleInfo.PerDay entries a day
+type Schedule struct {
+	Number  int
+	Enabled bool
+	Days    map[time.Weekday][]TimerEntry
+}
+
+// GetScheduleInfo returns the weekly schedules stored on the unit
+func (d *DaikinBRP069) GetScheduleInfo(ctx context.Context) (*ScheduleInfo, error) {
+	data, err := d.getResource(ctx, "aircon/get_scdltimer_info", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule info: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, NewCapabilityError("schedules not supported by this unit", nil)
+	}
+
+	format, err := parseRecordFormat(data["f_detail"])
+	if err != nil {
+		return nil, err
+	}
+
+	info := &ScheduleInfo{
+		Enabled: data["en_scdltimer"] == "1",
+		format:  format,
+	}
+	info.Active, _ = strconv.Atoi(data["active_no"])
+	info.Count, _ = strconv.Atoi(data["scdl_num"])
+	info.PerDay, _ = strconv.Atoi(data["scdl_per_day"])
+	for n := 1; n <= info.Count; n++ {
+		info.Names = append(info.Names, decodeValue(data[fmt.Sprintf("scdl%d_name", n)]))
+	}
+
+	return info, nil
+}
+
+// GetSchedule returns weekly schedule number, from 1
+func (d *DaikinBRP069) GetSchedule(ctx context.Context, number int) (*Schedule, error) {
+	info, err := d.GetScheduleInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := d.getResource(ctx, "aircon/get_scdltimer_body", map[string]string{"target": strconv.Itoa(number)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule %d: %w", number, err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("schedule %d not found", number)
+	}
+
+	schedule := &Schedule{
+		Number:  number,
+		Enabled: data["en_scdltimer"] == "1",
+		Days:    make(map[time.Weekday][]TimerEntry),
+	}
+	for day, key := range scheduleDays {
+		// The day's records arrive encoded as a whole, "/" included; they
+		// are digits and dashes so the value can be decoded before splitting
+		for _, record := range strings.Split(decodeValue(data[key]), "/") {
+			entry, ok, err := d.decodeTimerEntry(info.format, record)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				schedule.Days[day] = append(schedule.Days[day], entry)
+			}
+		}
+	}
+
+	return schedule, nil
+}
+
+// SetSchedule replaces a weekly schedule. Entries are stored in time order.
+func (d *DaikinBRP069) SetSchedule(ctx context.Context, schedule *Schedule) error {
+	info, err := d.GetScheduleInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	params := map[string]string{
+		"format":       "v1",
+		"target":       strconv.Itoa(schedule.Number),
+		"en_scdltimer": boolParam(schedule.Enabled),
+	}
+	for day, key := range scheduleDays {
+		entries := append([]TimerEntry(nil), schedule.Days[day]...)
+		if info.PerDay > 0 && len(entries) > info.PerDay {
+			return fmt.Errorf("too many entries on %s: %d, the unit stores %d", day, len(entries), info.PerDay)
+		}
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].Hour*60+entries[i].Minute < entries[j].Hour*60+entries[j].Minute
+		})
+
+		records := make([]string, len(entries))
+		for i, entry := range entries {
+			if records[i], err = d.encodeTimerEntry(info.format, entry); err != nil {
+				return err
+			}
+		}
+		// Records are digits and dashes; only their separator needs encoding
+		params[key] = strings.Join(records, encodeValue("/"))
+	}
+
+	d.Logger.Info("Setting schedule", "number", schedule.Number)
+	if err := d.command(ctx, rawQuery("aircon/set_scdltimer_body", params)); err != nil {
+		return fmt.Errorf("failed to set schedule %d: %w", schedule.Number, err)
+	}
+	return nil
+}
+
+// SetScheduleEnabled makes schedule number the active one and turns the
+// weekly schedule on, or turns it off
+func (d *DaikinBRP069) SetScheduleEnabled(ctx context.Context, number int, enabled bool) error {
+	params := map[string]string{
+		"en_scdltimer": boolParam(enabled),
+		"active_no":    strconv.Itoa(number),
+	}
+
+	d.Logger.Info("Setting schedule state", "number", number, "enabled", enabled)
+	if err := d.command(ctx, rawQuery("aircon/set_scdltimer", params)); err != nil {
+		return fmt.Errorf("failed to set schedule state: %w", err)
+	}
+	return nil
+}
+
+// GetTimers returns the on/off timers
+func (d *DaikinBRP069) GetTimers(ctx context.Context) ([]TimerEntry, error) {
+	data, format, err := d.getTimerData(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	count, _ := strconv.Atoi(data["timer_num"])
+	timers := make([]TimerEntry, 0, count)
+	for n := 1; n <= count; n++ {
+		entry, _, err := d.decodeTimerEntry(format, decodeValue(data[fmt.Sprintf("timer%d", n)]))
+		if err != nil {
+			return nil, err
+		}
+		timers = append(timers, entry)
+	}
+	return timers, nil
+}
+
+// SetTimers replaces the on/off timers; the unit has a fixed number of
+// them, and those beyond timers are cleared
+func (d *DaikinBRP069) SetTimers(ctx context.Context, timers []TimerEntry) error {
+	data, format, err := d.getTimerData(ctx)
+	if err != nil {
+		return err
+	}
+
+	count, _ := strconv.Atoi(data["timer_num"])
+	if len(timers) > count {
+		return fmt.Errorf("too many timers: %d, the unit has %d", len(timers), count)
+	}
+
+	params := map[string]string{"format": "v1"}
+	for i := 0; i < count; i++ {
+		key := fmt.Sprintf("timer%d", i+1)
+		if i >= len(timers) {
+			// Empty slots are all dashes, as the unit reports them
+			params[key] = strings.Repeat("-", format.width())
+			continue
+		}
+		if params[key], err = d.encodeTimerEntry(format, timers[i]); err != nil {
+			return err
+		}
+	}
+
+	d.Logger.Info("Setting timers", "count", len(timers))
+	if err := d.command(ctx, rawQuery("aircon/set_timer", params)); err != nil {
+		return fmt.Errorf("failed to set timers: %w", err)
+	}
+	return nil
+}
+
+func (d *DaikinBRP069) getTimerData(ctx context.Context) (map[string]string, recordFormat, error) {
+	data, err := d.getResource(ctx, "aircon/get_timer", nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get timers: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, nil, NewCapabilityError("timers not supported by this unit", nil)
+	}
+
+	format, err := parseRecordFormat(data["f_detail"])
+	return data, format, err
+}
+
+// decodeTimerEntry decodes a record, reporting false for an empty slot
+func (d *DaikinBRP069) decodeTimerEntry(format recordFormat, record string) (TimerEntry, bool, error) {
+	if strings.Trim(record, "-") == "" {
+		return TimerEntry{}, false, nil
+	}
+
+	fields, err := format.decode(record)
+	if err != nil {
+		return TimerEntry{}, false, err
+	}
+
+	entry := TimerEntry{
+		Enabled:      fields["en"] == "1",
+		Power:        fields["pow"] == "1",
+		Mode:         d.translateValue("mode", fields["mode"]),
+		FanRate:      d.translateValue("f_rate", fields["vol"]),
+		FanDirection: d.translateValue("f_dir", fields["dir"]),
+		Humidity:     fields["humi"],
+		SpecialMode:  fields["spmd"],
+		Temperature:  "--",
+	}
+
+	if clock, exists := fields["time"]; exists && len(clock) == 4 {
+		entry.Hour, _ = strconv.Atoi(clock[:2])
+		entry.Minute, _ = strconv.Atoi(clock[2:])
+	}
+
+	// Temperatures are in tenths of a degree, dashes when not set
+	if tenths, err := strconv.Atoi(fields["temp"]); err == nil {
+		entry.Temperature = strconv.FormatFloat(float64(tenths)/10, 'f', 1, 64)
+	}
+
+	return entry, true, nil
+}
+
+func (d *DaikinBRP069) encodeTimerEntry(format recordFormat, entry TimerEntry) (string, error) {
+	if entry.Hour < 0 || entry.Hour > 23 || entry.Minute < 0 || entry.Minute > 59 {
+		return "", fmt.Errorf("invalid timer time: %02d:%02d", entry.Hour, entry.Minute)
+	}
+
+	fields := map[string]string{
+		"en":   boolParam(entry.Enabled),
+		"pow":  boolParam(entry.Power),
+		"mode": d.reverseTranslateValue("mode", entry.Mode),
+		"time": fmt.Sprintf("%02d%02d", entry.Hour, entry.Minute),
+		"vol":  d.reverseTranslateValue("f_rate", entry.FanRate),
+		"dir":  d.reverseTranslateValue("f_dir", entry.FanDirection),
+		"humi": entry.Humidity,
+		"spmd": entry.SpecialMode,
+	}
+	if temperature, err := strconv.ParseFloat(entry.Temperature, 64); err == nil {
+		fields["temp"] = fmt.Sprintf("%04d", int(temperature*10+0.5))
+	}
+
+	return format.encode(fields)
+}
+
+// recordField is a fixed width field of a timer record
+type recordField struct {
+	name  string
+	width int
+}
+
+// recordFormat is the layout of timer records, as described by f_detail
+// ("total#18;_en#1;_pow#1;...")
+type recordFormat []recordField
+
+func parseRecordFormat(detail string) (recordFormat, error) {
+	detail = decodeValue(detail)
+	if detail == "" {
+		detail = defaultScheduleDetail
+	}
+
+	var format recordFormat
+	total := 0
+	for _, part := range strings.Split(detail, ";") {
+		name, width, found := strings.Cut(part, "#")
+		n, err := strconv.Atoi(width)
+		if !found || err != nil {
+			return nil, NewParseError(fmt.Sprintf("invalid record format %q", detail), err)
+		}
+		if name == "total" {
+			total = n
+			continue
+		}
+		format = append(format, recordField{name: strings.TrimPrefix(name, "_"), width: n})
+	}
+
+	if total != 0 && total != format.width() {
+		return nil, NewParseError(fmt.Sprintf("record format %q does not add up to %d", detail, total), nil)
+	}
+	return format, nil
+}
+
+func (f recordFormat) width() int {
+	total := 0
+	for _, field := range f {
+		total += field.width
+	}
+	return total
+}
+
+func (f recordFormat) decode(record string) (map[string]string, error) {
+	if len(record) != f.width() {
+		return nil, NewParseError(fmt.Sprintf("timer record %q is not %d characters", record, f.width()), nil)
+	}
+
+	fields := make(map[string]string, len(f))
+	offset := 0
+	for _, field := range f {
+		fields[field.name] = record[offset : offset+field.width]
+		offset += field.width
+	}
+	return fields, nil
+}
+
+// encode lays fields out as a record, right aligned and zero padded;
+// fields not given are filled with dashes
+func (f recordFormat) encode(fields map[string]string) (string, error) {
+	var record strings.Builder
+	for _, field := range f {
+		value, exists := fields[field.name]
+		switch {
+		case !exists || value == "":
+			value = strings.Repeat("-", field.width)
+		case len(value) > field.width:
+			return "", fmt.Errorf("timer field %s value %q is wider than %d", field.name, value, field.width)
+		default:
+			value = strings.Repeat("0", field.width-len(value)) + value
+		}
+		record.WriteString(value)
+	}
+	return record.String(), nil
+}
+
+func boolParam(value bool) string {
+	if value {
+		return "1"
+	}
+	return "0"
+}
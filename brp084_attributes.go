@@ -0,0 +1,103 @@
+package godaikin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Multireq response codes
+const (
+	RscReadOK  = 2000
+	RscWriteOK = 2004
+)
+
+// ReadAttributes reads arbitrary attributes in a single multireq. The
+// result holds the property found at each path; paths the adapter does not
+// know are left out. Paths of the default units (adr_0100 and adr_0200)
+// address this device's units, as for the driver's own attributes.
+func (d *DaikinBRP084) ReadAttributes(ctx context.Context, paths ...AttributePath) (attributes map[AttributePath]MultiReqProperty, err error) {
+	ctx, span := d.startSpan(ctx, "daikin.read_attributes")
+	defer func() { endSpan(span, err) }()
+
+	var resources []string
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		if to := d.resource(path.To); !seen[to] {
+			seen[to] = true
+			resources = append(resources, to+brp084StatusFilter)
+		}
+	}
+
+	response, err := d.multiRequest(ctx, newReadRequest(resources...))
+	if err != nil {
+		return nil, err
+	}
+
+	attributes = make(map[AttributePath]MultiReqProperty, len(paths))
+	for _, path := range paths {
+		property, err := response.Find(d.resolve(path))
+		if err != nil {
+			d.Logger.Debug("Attribute not found", "path", path.String(), "error", err)
+			continue
+		}
+		attributes[path] = *property
+	}
+
+	return attributes, nil
+}
+
+// WriteAttributes writes arbitrary attributes, given as hex 'pv' values, in
+// a single multireq, mapping paths to this device's units like
+// ReadAttributes. Nothing is sent if any path is malformed.
+func (d *DaikinBRP084) WriteAttributes(ctx context.Context, values map[AttributePath]string) (err error) {
+	ctx, span := d.startSpan(ctx, "daikin.write_attributes")
+	defer func() { endSpan(span, err) }()
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	paths := make([]AttributePath, 0, len(values))
+	for path := range values {
+		if err := path.validate(); err != nil {
+			return fmt.Errorf("invalid attribute path %q: %w", path.String(), err)
+		}
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool { return paths[i].String() < paths[j].String() })
+
+	var attributes []DaikinAttribute
+	for _, path := range paths {
+		attributes = append(attributes, NewDaikinAttribute(d.resolve(path), values[path]))
+	}
+
+	return d.write(ctx, attributes)
+}
+
+// write sends attributes as a multireq and checks every resource accepted
+// them
+func (d *DaikinBRP084) write(ctx context.Context, attributes []DaikinAttribute) error {
+	request := DaikinRequest{Attributes: attributes}
+	payload := request.Serialize(nil)
+	d.Logger.Debug("Sending request", "payload", payload)
+
+	response, err := d.multiRequest(ctx, payload)
+	if err != nil {
+		return err
+	}
+	d.Logger.Debug("Received response", "response", response)
+
+	var rejected []string
+	for _, r := range response.Responses {
+		if r.Rsc != RscWriteOK {
+			rejected = append(rejected, fmt.Sprintf("%s (rsc %d)", r.Fr, r.Rsc))
+		}
+	}
+	if len(rejected) > 0 {
+		return NewDaikinError("write rejected: "+strings.Join(rejected, ", "), nil)
+	}
+
+	return nil
+}
@@ -0,0 +1,208 @@
+package godaikin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Multireq operations
+const (
+	MultiReqOpRead  = 2
+	MultiReqOpWrite = 3
+)
+
+// MultiRequest is the body posted to dsiot/multireq
+type MultiRequest struct {
+	Requests []MultiReqRequest `json:"requests"`
+}
+
+// MultiReqRequest reads or writes one resource of the attribute tree
+type MultiReqRequest struct {
+	Op int               `json:"op"`
+	To string            `json:"to"`
+	PC *MultiReqProperty `json:"pc,omitempty"`
+}
+
+// MultiResponse is the body returned by dsiot/multireq, one response per
+// request
+type MultiResponse struct {
+	Responses []MultiReqResponse `json:"responses"`
+}
+
+// MultiReqResponse is the answer to one request. Rsc is 2000 for a
+// successful read and 2004 for a successful write.
+type MultiReqResponse struct {
+	Fr  string            `json:"fr"`
+	Rsc int               `json:"rsc"`
+	PC  *MultiReqProperty `json:"pc,omitempty"`
+}
+
+// MultiReqProperty is a node of the attribute tree. Leaves carry a value,
+// other nodes carry children.
+type MultiReqProperty struct {
+	Name     string             `json:"pn"`
+	Value    json.RawMessage    `json:"pv,omitempty"`
+	Type     int                `json:"pt,omitempty"`
+	Metadata json.RawMessage    `json:"md,omitempty"`
+	Children []MultiReqProperty `json:"pch,omitempty"`
+}
+
+// Hex returns the value of a leaf holding a hex string
+func (p *MultiReqProperty) Hex() (string, error) {
+	var value string
+	if err := json.Unmarshal(p.Value, &value); err != nil {
+		return "", fmt.Errorf("pv of %s is not a string: %w", p.Name, err)
+	}
+	return value, nil
+}
+
+// Text returns the value of a leaf in text form, whatever its JSON type
+func (p *MultiReqProperty) Text() (string, error) {
+	var value interface{}
+	if err := json.Unmarshal(p.Value, &value); err != nil {
+		return "", fmt.Errorf("invalid pv of %s: %w", p.Name, err)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprintf("%v", value), nil
+}
+
+// List returns the value of a leaf holding a list, each item in text form
+func (p *MultiReqProperty) List() ([]string, error) {
+	var values []interface{}
+	if err := json.Unmarshal(p.Value, &values); err != nil {
+		return nil, fmt.Errorf("pv of %s is not a list: %w", p.Name, err)
+	}
+
+	items := make([]string, len(values))
+	for i, value := range values {
+		items[i] = fmt.Sprintf("%v", value)
+	}
+	return items, nil
+}
+
+// child returns the direct child with the given name, nil if there is none
+func (p *MultiReqProperty) child(name string) *MultiReqProperty {
+	for i := range p.Children {
+		if p.Children[i].Name == name {
+			return &p.Children[i]
+		}
+	}
+	return nil
+}
+
+// AttributePath addresses an attribute of the tree: the resource holding it
+// and the slash separated chain of property names leading to it from the
+// resource root, for example
+//
+//	AttributePath{To: "/dsiot/edge/adr_0100.dgc_status", PN: "dgc_status/e_1002/e_A002/p_01"}
+type AttributePath struct {
+	To string
+	PN string
+}
+
+// ParseAttributePath parses a path written as the resource followed by the
+// property names, such as
+// "/dsiot/edge/adr_0100.dgc_status/dgc_status/e_1002/e_A002/p_01". The
+// resource is the part up to the first segment containing a dot.
+func ParseAttributePath(path string) (AttributePath, error) {
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i, segment := range segments {
+		if strings.Contains(segment, ".") {
+			parsed := AttributePath{
+				To: "/" + strings.Join(segments[:i+1], "/"),
+				PN: strings.Join(segments[i+1:], "/"),
+			}
+			if err := parsed.validate(); err != nil {
+				return AttributePath{}, fmt.Errorf("invalid attribute path %q: %w", path, err)
+			}
+			return parsed, nil
+		}
+	}
+	return AttributePath{}, fmt.Errorf("invalid attribute path %q", path)
+}
+
+// validate checks the path names a resource and at least the resource root
+// and one property below it, the shape every attribute has
+func (p AttributePath) validate() error {
+	if p.To == "" {
+		return errors.New("no resource")
+	}
+	names := p.names()
+	if len(names) < 2 {
+		return errors.New("needs the resource root and a property name")
+	}
+	for _, name := range names {
+		if name == "" {
+			return errors.New("empty property name")
+		}
+	}
+	return nil
+}
+
+func (p AttributePath) String() string {
+	return p.To + "/" + p.PN
+}
+
+func (p AttributePath) names() []string {
+	return strings.Split(p.PN, "/")
+}
+
+// Find returns the property at path, or an error if the response does not
+// contain it
+func (r *MultiResponse) Find(path AttributePath) (*MultiReqProperty, error) {
+	for i := range r.Responses {
+		response := &r.Responses[i]
+		if response.Fr != path.To {
+			continue
+		}
+		if response.PC == nil {
+			return nil, fmt.Errorf("%s: empty response (rsc %d)", path.To, response.Rsc)
+		}
+
+		names := path.names()
+		node := response.PC
+		if node.Name != names[0] {
+			return nil, fmt.Errorf("%s: %s not found", path, names[0])
+		}
+		for _, name := range names[1:] {
+			if node = node.child(name); node == nil {
+				return nil, fmt.Errorf("%s: %s not found", path, name)
+			}
+		}
+		return node, nil
+	}
+	return nil, fmt.Errorf("%s: no response for %s", path, path.To)
+}
+
+// responseCodes returns the 'rsc' code of every response
+func (r *MultiResponse) responseCodes() []int {
+	rscs := make([]int, len(r.Responses))
+	for i, response := range r.Responses {
+		rscs[i] = response.Rsc
+	}
+	return rscs
+}
+
+// targets returns the resource each request reads or writes, without the
+// filter query
+func (r *MultiRequest) targets() []string {
+	targets := make([]string, len(r.Requests))
+	for i, request := range r.Requests {
+		targets[i], _, _ = strings.Cut(request.To, "?")
+	}
+	return targets
+}
+
+// newReadRequest builds a multireq reading the given resources with their
+// values, types and metadata
+func newReadRequest(resources ...string) *MultiRequest {
+	request := &MultiRequest{}
+	for _, to := range resources {
+		request.Requests = append(request.Requests, MultiReqRequest{Op: MultiReqOpRead, To: to})
+	}
+	return request
+}
@@ -0,0 +1,83 @@
+package godaikin
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// BRP084 attribute values ('pv') are hex strings of little-endian integers:
+// "0300" is 3, "2C00" is 44. Temperatures are signed and scaled by a
+// divisor, 2 for setpoints and the outdoor sensor (half degrees) and 1 for
+// the indoor sensor.
+
+// decodePVUint decodes a little-endian unsigned value
+func decodePVUint(pv string) (uint64, error) {
+	data, err := hex.DecodeString(pv)
+	if err != nil {
+		return 0, NewParseError(fmt.Sprintf("invalid hex value %q", pv), err)
+	}
+	if len(data) == 0 || len(data) > 8 {
+		return 0, NewParseError(fmt.Sprintf("invalid hex value length %q", pv), nil)
+	}
+
+	var value uint64
+	for i := len(data) - 1; i >= 0; i-- {
+		value = value<<8 | uint64(data[i])
+	}
+	return value, nil
+}
+
+// decodePVInt decodes a little-endian two's complement value
+func decodePVInt(pv string) (int64, error) {
+	value, err := decodePVUint(pv)
+	if err != nil {
+		return 0, err
+	}
+
+	bits := uint(len(pv) / 2 * 8)
+	if bits < 64 && value&(1<<(bits-1)) != 0 {
+		return int64(value) - int64(1)<<bits, nil
+	}
+	return int64(value), nil
+}
+
+// encodePVUint encodes value as a little-endian value of width bytes
+func encodePVUint(value uint64, width int) string {
+	data := make([]byte, width)
+	for i := range data {
+		data[i] = byte(value >> (8 * i))
+	}
+	return strings.ToUpper(hex.EncodeToString(data))
+}
+
+// decodePVTemperature decodes a signed temperature scaled by divisor
+func decodePVTemperature(pv string, divisor int) (float64, error) {
+	value, err := decodePVInt(pv)
+	if err != nil {
+		return 0, err
+	}
+	return float64(value) / float64(divisor), nil
+}
+
+// encodePVTemperature encodes a temperature scaled by divisor as a signed
+// value of width bytes
+func encodePVTemperature(temperature float64, divisor, width int) string {
+	value := int64(math.Round(temperature * float64(divisor)))
+	return encodePVUint(uint64(value), width)
+}
+
+// pvBits is a bitfield value such as the swing settings
+type pvBits uint64
+
+// decodePVBits decodes a little-endian bitfield
+func decodePVBits(pv string) (pvBits, error) {
+	value, err := decodePVUint(pv)
+	return pvBits(value), err
+}
+
+// has reports whether all bits of mask are set
+func (b pvBits) has(mask pvBits) bool {
+	return b&mask == mask
+}
@@ -0,0 +1,162 @@
+package godaikin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	brp084Edge           = "/dsiot/edge/"
+	brp084DefaultIndoor  = "adr_0100"
+	brp084DefaultOutdoor = "adr_0200"
+
+	// Indoor units are addressed from adr_0100 and outdoor units from
+	// adr_0200; these bound the addresses probed by Units. Only the first
+	// of each is confirmed by a capture, the rest of the ranges are an
+	// assumption.
+	brp084MaxIndoorUnits  = 8
+	brp084MaxOutdoorUnits = 4
+)
+
+// BRP084Unit is an indoor unit behind a BRP084 adapter and the outdoor unit
+// it is connected to. OutdoorAddress is empty when that is not known.
+type BRP084Unit struct {
+	Address        string
+	OutdoorAddress string
+}
+
+// Units enumerates the indoor units behind the adapter by probing
+// adr_0100 to adr_0107 and adr_0200 to adr_0203. Experimental: the adapter
+// does not report which outdoor unit an indoor unit is connected to, so
+// units are only paired when there is a single outdoor unit, which they
+// all share. With several outdoor units OutdoorAddress is left empty; set
+// it before calling ForUnit.
+func (d *DaikinBRP084) Units(ctx context.Context) (units []BRP084Unit, err error) {
+	ctx, span := d.startSpan(ctx, "daikin.units")
+	defer func() { endSpan(span, err) }()
+
+	var resources []string
+	for i := 0; i < brp084MaxIndoorUnits; i++ {
+		resources = append(resources, unitStatus(unitAddress(0x0100+i))+brp084StatusFilter)
+	}
+	for i := 0; i < brp084MaxOutdoorUnits; i++ {
+		resources = append(resources, unitStatus(unitAddress(0x0200+i))+brp084StatusFilter)
+	}
+
+	response, err := d.multiRequest(ctx, newReadRequest(resources...))
+	if err != nil {
+		return nil, err
+	}
+
+	var outdoor []string
+	for i := 0; i < brp084MaxOutdoorUnits; i++ {
+		if address := unitAddress(0x0200 + i); unitPresent(response, address, "e_1003") {
+			outdoor = append(outdoor, address)
+		}
+	}
+
+	shared := ""
+	if len(outdoor) == 1 {
+		shared = outdoor[0]
+	}
+
+	for i := 0; i < brp084MaxIndoorUnits; i++ {
+		if address := unitAddress(0x0100 + i); unitPresent(response, address, "e_1002") {
+			units = append(units, BRP084Unit{Address: address, OutdoorAddress: shared})
+		}
+	}
+
+	if len(units) == 0 {
+		return nil, fmt.Errorf("no indoor units found")
+	}
+
+	return units, nil
+}
+
+// ForUnit returns a device controlling the given unit. It shares the
+// adapter's HTTP client, tracer and logger but keeps its own values. When
+// the unit's outdoor address is unknown the adapter's is used. The
+// adapter's metrics stay with the adapter, so closing the unit leaves them
+// registered.
+func (d *DaikinBRP084) ForUnit(unit BRP084Unit) *DaikinBRP084 {
+	base := *d.BaseAppliance
+	base.Values = NewValues()
+	base.metrics = nil
+
+	outdoor := unit.OutdoorAddress
+	if outdoor == "" {
+		outdoor = d.OutdoorAddress
+	}
+
+	return &DaikinBRP084{
+		BaseAppliance:  &base,
+		URL:            d.URL,
+		IndoorAddress:  unit.Address,
+		OutdoorAddress: outdoor,
+	}
+}
+
+// Appliances enumerates the indoor units and returns an initialized device
+// for each
+func (d *DaikinBRP084) Appliances(ctx context.Context) ([]Appliance, error) {
+	units, err := d.Units(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	appliances := make([]Appliance, 0, len(units))
+	for _, unit := range units {
+		device := d.ForUnit(unit)
+		if err := device.UpdateStatus(ctx); err != nil {
+			return nil, fmt.Errorf("failed to update unit %s: %w", unit.Address, err)
+		}
+		appliances = append(appliances, device)
+	}
+
+	return appliances, nil
+}
+
+// UnitsByOutdoor groups indoor unit addresses by the outdoor unit they
+// share, units whose outdoor unit is unknown under ""
+func UnitsByOutdoor(units []BRP084Unit) map[string][]string {
+	groups := make(map[string][]string)
+	for _, unit := range units {
+		groups[unit.OutdoorAddress] = append(groups[unit.OutdoorAddress], unit.Address)
+	}
+	return groups
+}
+
+// resource maps a resource of the default units to the units of this device
+func (d *DaikinBRP084) resource(to string) string {
+	for address, replacement := range map[string]string{
+		brp084DefaultIndoor:  d.IndoorAddress,
+		brp084DefaultOutdoor: d.OutdoorAddress,
+	} {
+		prefix := brp084Edge + address + "."
+		if replacement != "" && strings.HasPrefix(to, prefix) {
+			return brp084Edge + replacement + "." + strings.TrimPrefix(to, prefix)
+		}
+	}
+	return to
+}
+
+// resolve maps a path of the registry to the units of this device
+func (d *DaikinBRP084) resolve(path AttributePath) AttributePath {
+	return AttributePath{To: d.resource(path.To), PN: path.PN}
+}
+
+func unitAddress(n int) string {
+	return fmt.Sprintf("adr_%04X", n)
+}
+
+func unitStatus(address string) string {
+	return brp084Edge + address + ".dgc_status"
+}
+
+// unitPresent reports whether the response holds the status of a unit with
+// the given section, e_1002 for indoor units and e_1003 for outdoor units
+func unitPresent(response *MultiResponse, address, section string) bool {
+	_, err := response.Find(AttributePath{To: unitStatus(address), PN: "dgc_status/" + section})
+	return err == nil
+}
@@ -0,0 +1,300 @@
+package godaikin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+const cassetteVersion = 1
+
+// Cassette holds HTTP exchanges recorded from a Daikin device
+type Cassette struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded request and its outcome
+type Interaction struct {
+	Request  RecordedRequest   `json:"request"`
+	Response *RecordedResponse `json:"response,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// RecordedRequest is the redacted form of a request sent to a device
+type RecordedRequest struct {
+	Method string            `json:"method"`
+	Path   string            `json:"path"`
+	Query  string            `json:"query,omitempty"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   string            `json:"body,omitempty"`
+}
+
+// RecordedResponse is a response returned by a device
+type RecordedResponse struct {
+	StatusCode int               `json:"status_code"`
+	Header     map[string]string `json:"header,omitempty"`
+	Body       string            `json:"body"`
+}
+
+// LoadCassette reads a cassette from a JSON file
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, NewParseError("invalid cassette file", err)
+	}
+
+	if cassette.Version != cassetteVersion {
+		return nil, NewParseError(fmt.Sprintf("unsupported cassette version: %d", cassette.Version), nil)
+	}
+
+	return &cassette, nil
+}
+
+// Save writes the cassette to a JSON file
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+
+	return nil
+}
+
+// RecordingTransport is an http.RoundTripper that records every exchange
+// into a cassette, with credentials and identifiers (MAC addresses, UUIDs)
+// redacted from requests and responses
+type RecordingTransport struct {
+	// Transport performs the actual requests. If nil, a device given the
+	// recorder with WithTransport keeps its own transport, such as the TLS
+	// settings of BRP072C; used directly, it is http.DefaultTransport.
+	Transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecordingTransport creates a recording transport wrapping next
+func NewRecordingTransport(next http.RoundTripper) *RecordingTransport {
+	return &RecordingTransport{
+		Transport: next,
+		cassette:  Cassette{Version: cassetteVersion},
+	}
+}
+
+func (r *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return r.roundTrip(req, r.Transport)
+}
+
+// wrap returns a transport recording into this cassette through next, the
+// device's own transport, unless Transport is set
+func (r *RecordingTransport) wrap(next http.RoundTripper) http.RoundTripper {
+	if r.Transport != nil || next == nil {
+		return r
+	}
+	return &boundRecordingTransport{recorder: r, next: next}
+}
+
+// boundRecordingTransport records into a RecordingTransport through the
+// transport of one device
+type boundRecordingTransport struct {
+	recorder *RecordingTransport
+	next     http.RoundTripper
+}
+
+func (b *boundRecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return b.recorder.roundTrip(req, b.next)
+}
+
+func (r *RecordingTransport) roundTrip(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	recorded, req, err := recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	interaction := Interaction{Request: recorded}
+
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		interaction.Error = redactSecrets(err.Error())
+		r.append(interaction)
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		interaction.Error = redactSecrets(err.Error())
+		r.append(interaction)
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	interaction.Response = &RecordedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     flattenHeader(resp.Header),
+		Body:       redactIdentifiers(redactSecrets(string(body))),
+	}
+	r.append(interaction)
+
+	return resp, nil
+}
+
+func (r *RecordingTransport) append(interaction Interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+}
+
+// Cassette returns a copy of the exchanges recorded so far
+func (r *RecordingTransport) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	interactions := make([]Interaction, len(r.cassette.Interactions))
+	copy(interactions, r.cassette.Interactions)
+	return &Cassette{Version: r.cassette.Version, Interactions: interactions}
+}
+
+// Save writes the exchanges recorded so far to a JSON file
+func (r *RecordingTransport) Save(path string) error {
+	return r.Cassette().Save(path)
+}
+
+// ReplayTransport is an http.RoundTripper that serves responses from a
+// cassette. Requests are matched on method, path, query and body; the host
+// is ignored so a cassette can be replayed against any address. Repeated
+// requests are answered in recorded order and the last answer is repeated
+// once they are exhausted.
+type ReplayTransport struct {
+	mu           sync.Mutex
+	interactions map[string][]Interaction
+	served       map[string]int
+}
+
+// NewReplayTransport creates a replay transport for the given cassette
+func NewReplayTransport(cassette *Cassette) *ReplayTransport {
+	r := &ReplayTransport{
+		interactions: make(map[string][]Interaction),
+		served:       make(map[string]int),
+	}
+	for _, interaction := range cassette.Interactions {
+		key := interaction.Request.key()
+		r.interactions[key] = append(r.interactions[key], interaction)
+	}
+	return r
+}
+
+func (r *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, req, err := recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	key := recorded.key()
+
+	r.mu.Lock()
+	candidates := r.interactions[key]
+	index := r.served[key]
+	if index < len(candidates) {
+		r.served[key] = index + 1
+	}
+	r.mu.Unlock()
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no recorded interaction for %s %s", recorded.Method, recorded.Path)
+	}
+	if index >= len(candidates) {
+		index = len(candidates) - 1
+	}
+
+	interaction := candidates[index]
+	if interaction.Response == nil {
+		return nil, fmt.Errorf("replayed error: %s", interaction.Error)
+	}
+
+	header := make(http.Header)
+	for name, value := range interaction.Response.Header {
+		header.Set(name, value)
+	}
+
+	code := interaction.Response.StatusCode
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", code, http.StatusText(code)),
+		StatusCode:    code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+		ContentLength: int64(len(interaction.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// recordRequest builds the redacted form of req. The request body is
+// consumed, so a copy of req with a fresh body is returned alongside.
+func recordRequest(req *http.Request) (RecordedRequest, *http.Request, error) {
+	recorded := RecordedRequest{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  redactQuery(req.URL.RawQuery),
+		Header: flattenHeader(req.Header),
+	}
+
+	for name := range recorded.Header {
+		if secretHeaders[name] {
+			recorded.Header[name] = redactedValue
+		}
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return recorded, req, NewConnectionError("failed to read request body", err)
+		}
+		recorded.Body = string(body)
+
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	return recorded, req, nil
+}
+
+func (r RecordedRequest) key() string {
+	return r.Method + " " + r.Path + "?" + r.Query + "\n" + r.Body
+}
+
+func flattenHeader(header http.Header) map[string]string {
+	if len(header) == 0 {
+		return nil
+	}
+
+	result := make(map[string]string, len(header))
+	for name, values := range header {
+		if len(values) > 0 {
+			result[http.CanonicalHeaderKey(name)] = values[0]
+		}
+	}
+	return result
+}
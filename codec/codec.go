@@ -0,0 +1,354 @@
+// Package codec encodes and decodes the Daikin key=value format, such as
+// "ret=OK,pow=1,mode=3,stemp=24.0", into structs using `daikin` field tags.
+//
+// The tag holds the key name followed by optional comma separated options:
+//
+//	Power       bool     `daikin:"pow"`
+//	Temperature *float64 `daikin:"htemp"`
+//	History     []int    `daikin:"curr_year_heat,sep=/"`
+//	Name        string   `daikin:"name,omitempty"`
+//
+// Strings are percent-decoded. Slices are split on ";" unless another
+// separator is given with sep=, then each item is percent-decoded, so an
+// encoded separator stays inside its item. Numbers accept the "-" and "--"
+// sentinels the devices report for unavailable readings; they decode to nil for
+// pointer fields and to zero otherwise. Pointer fields are optional: they
+// are nil when the key is missing and are omitted by Marshal when nil.
+// Fields without a tag, and keys without a field, are ignored.
+package codec
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	tagName          = "daikin"
+	defaultSeparator = ";"
+)
+
+// UnmarshalError describes a value that could not be decoded into a field
+type UnmarshalError struct {
+	Key   string
+	Value string
+	Type  reflect.Type
+	Err   error
+}
+
+func (e *UnmarshalError) Error() string {
+	return fmt.Sprintf("codec: cannot decode %s=%q into %s: %v", e.Key, e.Value, e.Type, e.Err)
+}
+
+func (e *UnmarshalError) Unwrap() error {
+	return e.Err
+}
+
+// Unmarshal decodes a key=value body into the struct pointed to by v
+func Unmarshal(data []byte, v interface{}) error {
+	return UnmarshalValues(Parse(data), v)
+}
+
+// UnmarshalValues decodes already split values into the struct pointed to
+// by v
+func UnmarshalValues(values map[string]string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("codec: Unmarshal requires a non-nil struct pointer, got %T", v)
+	}
+
+	for _, f := range fieldsOf(rv.Elem().Type()) {
+		raw, exists := values[f.key]
+		if !exists {
+			continue
+		}
+
+		if err := f.decode(rv.Elem().FieldByIndex(f.index), raw); err != nil {
+			return &UnmarshalError{Key: f.key, Value: raw, Type: f.typ, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// Marshal encodes the struct v as a key=value body, in field order. String
+// values and items are percent-encoded by Escape; numbers and booleans never
+// need encoding and are written as they are.
+func Marshal(v interface{}) ([]byte, error) {
+	rv, err := structValue(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var pairs []string
+	for _, f := range fieldsOf(rv.Type()) {
+		items, ok := f.encode(rv.FieldByIndex(f.index))
+		if !ok {
+			continue
+		}
+		if elemKind(f.typ) == reflect.String {
+			for i, item := range items {
+				items[i] = Escape(item)
+			}
+		}
+		pairs = append(pairs, f.key+"="+strings.Join(items, f.separator))
+	}
+
+	return []byte(strings.Join(pairs, ",")), nil
+}
+
+// MarshalValues encodes the struct v as unescaped values, ready to be sent
+// as query parameters
+func MarshalValues(v interface{}) (map[string]string, error) {
+	rv, err := structValue(v)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	for _, f := range fieldsOf(rv.Type()) {
+		if items, ok := f.encode(rv.FieldByIndex(f.index)); ok {
+			values[f.key] = strings.Join(items, f.separator)
+		}
+	}
+
+	return values, nil
+}
+
+// Parse splits a key=value body into a map, leaving values encoded
+func Parse(data []byte) map[string]string {
+	return Split(string(data), ",")
+}
+
+// Split splits a body of key=value pairs joined by separator, "," for most
+// adapters and "&" for SkyFi. Values are left encoded so they can be sent
+// back unchanged.
+func Split(body, separator string) map[string]string {
+	values := make(map[string]string)
+	for _, pair := range strings.Split(strings.TrimSpace(body), separator) {
+		key, value, found := strings.Cut(pair, "=")
+		if found {
+			values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return values
+}
+
+// Unescape percent-decodes a value, returning it unchanged if it is not
+// validly encoded. Unlike a query string, "+" stands for itself.
+func Unescape(value string) string {
+	if decoded, err := url.PathUnescape(value); err == nil {
+		return decoded
+	}
+	return value
+}
+
+// Escape percent-encodes every byte of a value the way the adapters report
+// names ("%4c%69...")
+func Escape(value string) string {
+	var encoded strings.Builder
+	for _, b := range []byte(value) {
+		fmt.Fprintf(&encoded, "%%%02x", b)
+	}
+	return encoded.String()
+}
+
+// SplitList splits a list value on separator and percent-decodes each
+// item. Splitting first keeps an encoded separator inside its item.
+func SplitList(value, separator string) []string {
+	if value == "" {
+		return nil
+	}
+	items := strings.Split(value, separator)
+	for i, item := range items {
+		items[i] = Unescape(item)
+	}
+	return items
+}
+
+func structValue(v interface{}) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return rv, fmt.Errorf("codec: Marshal of nil %T", v)
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return rv, fmt.Errorf("codec: Marshal requires a struct, got %T", v)
+	}
+	return rv, nil
+}
+
+type field struct {
+	key       string
+	index     []int
+	typ       reflect.Type
+	omitEmpty bool
+	separator string
+}
+
+func fieldsOf(t reflect.Type) []field {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup(tagName)
+		if !ok || tag == "-" || !sf.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		f := field{key: name, index: sf.Index, typ: sf.Type, separator: defaultSeparator}
+		for _, option := range strings.Split(options, ",") {
+			switch {
+			case option == "omitempty":
+				f.omitEmpty = true
+			case strings.HasPrefix(option, "sep="):
+				f.separator = strings.TrimPrefix(option, "sep=")
+			}
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+func (f field) decode(v reflect.Value, raw string) error {
+	if v.Kind() == reflect.Pointer {
+		if isSentinel(raw) && isNumeric(v.Type().Elem().Kind()) {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		target := reflect.New(v.Type().Elem())
+		if err := f.decode(target.Elem(), raw); err != nil {
+			return err
+		}
+		v.Set(target)
+		return nil
+	}
+
+	if v.Kind() == reflect.Slice {
+		if raw == "" {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		items := SplitList(raw, f.separator)
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decodeScalar(slice.Index(i), item); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+
+	return decodeScalar(v, Unescape(raw))
+}
+
+func decodeScalar(v reflect.Value, raw string) error {
+	if isNumeric(v.Kind()) && isSentinel(raw) {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// encode returns the unescaped value of a field, one item per slice
+// element, and whether it should be written at all
+func (f field) encode(v reflect.Value) ([]string, bool) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+
+	if f.omitEmpty && v.IsZero() {
+		return nil, false
+	}
+
+	if v.Kind() == reflect.Slice {
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = encodeScalar(v.Index(i))
+		}
+		return items, true
+	}
+
+	return []string{encodeScalar(v)}, true
+}
+
+func encodeScalar(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return "1"
+		}
+		return "0"
+	case reflect.Float32, reflect.Float64:
+		// The devices send and expect at least one decimal, as in "24.0"
+		f := v.Float()
+		if f == float64(int64(f)) {
+			return strconv.FormatFloat(f, 'f', 1, v.Type().Bits())
+		}
+		return strconv.FormatFloat(f, 'f', -1, v.Type().Bits())
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// elemKind returns the kind of a field's scalar values, looking through
+// pointers and slices
+func elemKind(t reflect.Type) reflect.Kind {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t.Kind()
+}
+
+func isSentinel(raw string) bool {
+	return raw == "-" || raw == "--"
+}
+
+func isNumeric(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
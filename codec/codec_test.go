@@ -0,0 +1,95 @@
+package codec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sample struct {
+	Power       bool      `daikin:"pow"`
+	Name        string    `daikin:"name"`
+	Temperature *float64  `daikin:"htemp"`
+	Humidity    *float64  `daikin:"hhum"`
+	Outside     float64   `daikin:"otemp"`
+	Zones       []string  `daikin:"zone_name"`
+	History     []int     `daikin:"curr_day_heat,sep=/"`
+	Setpoints   []float64 `daikin:"lztemp_h"`
+	Optional    *int      `daikin:"en_zone"`
+	Comment     string    `daikin:"comment,omitempty"`
+	Ignored     string
+}
+
+func TestUnmarshal(t *testing.T) {
+	body := "ret=OK,pow=1,name=%4c%69%76%69%6e%67,htemp=22.5,hhum=-,otemp=--," +
+		"zone_name=Living;Bed%201,curr_day_heat=1/2/3,lztemp_h=21.0;-,extra=1"
+
+	var s sample
+	assert.NoError(t, Unmarshal([]byte(body), &s))
+	assert.True(t, s.Power)
+	assert.Equal(t, "Living", s.Name)
+	assert.Equal(t, 22.5, *s.Temperature)
+	assert.Nil(t, s.Humidity)
+	assert.Equal(t, 0.0, s.Outside)
+	assert.Equal(t, []string{"Living", "Bed 1"}, s.Zones)
+	assert.Equal(t, []int{1, 2, 3}, s.History)
+	assert.Equal(t, []float64{21, 0}, s.Setpoints)
+	assert.Nil(t, s.Optional)
+
+	var bad sample
+	err := Unmarshal([]byte("ret=OK,htemp=warm"), &bad)
+	var unmarshalErr *UnmarshalError
+	assert.ErrorAs(t, err, &unmarshalErr)
+	assert.Equal(t, "htemp", unmarshalErr.Key)
+
+	assert.Error(t, Unmarshal([]byte("pow=1"), sample{}))
+
+	// Lists are split before decoding, so an encoded separator and a "+"
+	// stay inside their item
+	var zones sample
+	assert.NoError(t, Unmarshal([]byte("zone_name=a%3bb;c+d"), &zones))
+	assert.Equal(t, []string{"a;b", "c+d"}, zones.Zones)
+}
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, "%48%6f%6d%65%20%c3%bc", Escape("Home ü"))
+	assert.Equal(t, "Home ü", Unescape(Escape("Home ü")))
+	assert.Equal(t, "a+b", Unescape("a+b"))
+	assert.Equal(t, "100%", Unescape("100%"))
+	assert.Equal(t, map[string]string{"a": "1", "b": "x%20y"}, Split(" a=1&b=x%20y&junk", "&"))
+	assert.Nil(t, SplitList("", ";"))
+}
+
+func TestMarshal(t *testing.T) {
+	temperature := 24.0
+	s := sample{
+		Power:       true,
+		Name:        "Bed 1",
+		Temperature: &temperature,
+		Outside:     8.5,
+		Zones:       []string{"a", "b"},
+		History:     []int{1, 2},
+	}
+
+	values, err := MarshalValues(s)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"pow":           "1",
+		"name":          "Bed 1",
+		"htemp":         "24.0",
+		"otemp":         "8.5",
+		"zone_name":     "a;b",
+		"curr_day_heat": "1/2",
+		"lztemp_h":      "",
+	}, values)
+
+	body, err := Marshal(&s)
+	assert.NoError(t, err)
+	assert.Equal(t, "pow=1,name=%42%65%64%20%31,htemp=24.0,otemp=8.5,zone_name=%61;%62,curr_day_heat=1/2,lztemp_h=", string(body))
+
+	var decoded sample
+	assert.NoError(t, Unmarshal(body, &decoded))
+	assert.Equal(t, s.Name, decoded.Name)
+	assert.Equal(t, s.Zones, decoded.Zones)
+	assert.Equal(t, s.History, decoded.History)
+}
@@ -0,0 +1,135 @@
+package godaikin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Credentials are the secrets used to talk to a device
+type Credentials struct {
+	// Password authenticates SkyFi devices
+	Password string `json:"password,omitempty"`
+	// Key registers BRP072C terminals
+	Key string `json:"key,omitempty"`
+	// UUID identifies the BRP072C terminal
+	UUID string `json:"uuid,omitempty"`
+}
+
+// CredentialProvider supplies credentials whenever a device needs them, so
+// they can be rotated without reconnecting
+type CredentialProvider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// StaticCredentials is a CredentialProvider returning fixed credentials
+type StaticCredentials Credentials
+
+func (s StaticCredentials) Credentials(context.Context) (Credentials, error) {
+	return Credentials(s), nil
+}
+
+// CredentialsFunc adapts a function to a CredentialProvider
+type CredentialsFunc func(ctx context.Context) (Credentials, error)
+
+func (f CredentialsFunc) Credentials(ctx context.Context) (Credentials, error) {
+	return f(ctx)
+}
+
+// EnvCredentials reads credentials from the <prefix>_PASSWORD, <prefix>_KEY
+// and <prefix>_UUID environment variables each time they are needed
+type EnvCredentials struct {
+	Prefix string
+}
+
+func (e EnvCredentials) Credentials(context.Context) (Credentials, error) {
+	prefix := e.Prefix
+	if prefix == "" {
+		prefix = "DAIKIN"
+	}
+	return Credentials{
+		Password: os.Getenv(prefix + "_PASSWORD"),
+		Key:      os.Getenv(prefix + "_KEY"),
+		UUID:     os.Getenv(prefix + "_UUID"),
+	}, nil
+}
+
+// FileCredentials reads credentials from a JSON file with "password", "key"
+// and "uuid" fields. The file is read again whenever it changes.
+type FileCredentials struct {
+	Path string
+
+	mu          sync.Mutex
+	modTime     time.Time
+	credentials Credentials
+}
+
+// NewFileCredentials creates a provider reading the given JSON file
+func NewFileCredentials(path string) *FileCredentials {
+	return &FileCredentials{Path: path}
+}
+
+func (f *FileCredentials) Credentials(context.Context) (Credentials, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	if info.ModTime().Equal(f.modTime) {
+		return f.credentials, nil
+	}
+
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	var credentials Credentials
+	if err := json.Unmarshal(data, &credentials); err != nil {
+		return Credentials{}, NewParseError("invalid credentials file", err)
+	}
+
+	f.credentials = credentials
+	f.modTime = info.ModTime()
+	return credentials, nil
+}
+
+// WithCredentialProvider fetches the device credentials from provider.
+// Values it leaves empty fall back to WithPassword, WithKey and WithUUID.
+func WithCredentialProvider(provider CredentialProvider) Option {
+	return func(c *Config) {
+		c.Credentials = provider
+	}
+}
+
+// credentialProvider returns the provider backing the configured credentials
+func (c *Config) credentialProvider() CredentialProvider {
+	static := StaticCredentials{Password: c.Password, Key: c.Key, UUID: c.UUID}
+	if c.Credentials == nil {
+		return static
+	}
+
+	provider := c.Credentials
+	return CredentialsFunc(func(ctx context.Context) (Credentials, error) {
+		credentials, err := provider.Credentials(ctx)
+		if err != nil {
+			return Credentials{}, err
+		}
+		if credentials.Password == "" {
+			credentials.Password = static.Password
+		}
+		if credentials.Key == "" {
+			credentials.Key = static.Key
+		}
+		if credentials.UUID == "" {
+			credentials.UUID = static.UUID
+		}
+		return credentials, nil
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // DaikinAirBase represents a Daikin AirBase device (BRP15B61)
@@ -51,6 +52,7 @@ func NewDaikinAirBase(deviceIP string, logger Logger) *DaikinAirBase {
 		"aircon/get_control_info",
 		"aircon/get_zone_setting",
 	}
+	base.deviceType = "AirBase"
 
 	return &DaikinAirBase{BaseAppliance: base}
 }
@@ -99,7 +101,10 @@ func (d *DaikinAirBase) Init(ctx context.Context) error {
 	return nil
 }
 
-func (d *DaikinAirBase) UpdateStatus(ctx context.Context) error {
+func (d *DaikinAirBase) UpdateStatus(ctx context.Context) (err error) {
+	ctx, span := d.startSpan(ctx, "daikin.update_status")
+	defer func() { endSpan(span, err) }()
+
 	// Use skyfi/ prefix for info resources
 	for _, resource := range d.InfoResources {
 		skyfiResource := "skyfi/" + resource
@@ -118,8 +123,11 @@ func (d *DaikinAirBase) UpdateStatus(ctx context.Context) error {
 	return nil
 }
 
-func (d *DaikinAirBase) Set(ctx context.Context, settings map[string]string) error {
-	err := d.updateSettings(ctx, settings)
+func (d *DaikinAirBase) Set(ctx context.Context, settings map[string]string) (err error) {
+	ctx, span := d.startSpan(ctx, "daikin.set")
+	defer func() { endSpan(span, err) }()
+
+	err = d.updateSettings(ctx, settings)
 	if err != nil {
 		return fmt.Errorf("failed to update settings: %w", err)
 	}
@@ -230,14 +238,23 @@ func (d *DaikinAirBase) Represent(key string) (string, interface{}) {
 
 	if key == "zone_name" || key == "zone_onoff" || key == "lztemp_c" || key == "lztemp_h" {
 		if value, exists := d.Values.Get(key); exists {
-			decoded, _ := url.QueryUnescape(value)
-			return k, strings.Split(decoded, ";")
+			return k, decodeZoneList(value)
 		}
 	}
 
 	return k, val
 }
 
+// zoneSeparator restores the ";" separators of zone lists, which the
+// adapter percent-encodes along with the items
+var zoneSeparator = strings.NewReplacer("%3b", ";", "%3B", ";")
+
+// decodeZoneList splits a zone list, its separators encoded or not. Zone
+// names cannot contain ";".
+func decodeZoneList(value string) []string {
+	return decodeList(zoneSeparator.Replace(value), ";")
+}
+
 func (d *DaikinAirBase) representBase(key string) (string, interface{}) {
 	k := key
 
@@ -406,18 +423,65 @@ func (d *DaikinAirBase) SetZone(ctx context.Context, zoneID int, key string, val
 		params["lztemp_h"] = d.Values.All()["lztemp_h"]
 	}
 
-	// Build query string manually to avoid double encoding
-	var queryParts []string
-	for k, v := range params {
-		queryParts = append(queryParts, fmt.Sprintf("%s=%s", k, v))
+	d.Logger.Info("Updating zone setting", "zone_id", zoneID, "key", key, "value", value)
+	if err := d.command(ctx, rawQuery("skyfi/aircon/set_zone_setting", params)); err != nil {
+		return fmt.Errorf("failed to set zone setting: %w", err)
 	}
-	path := "skyfi/aircon/set_zone_setting?" + strings.Join(queryParts, "&")
 
-	d.Logger.Info("Updating zone setting", "zone_id", zoneID, "key", key, "value", value)
-	_, err = d.getResource(ctx, path, nil)
+	return nil
+}
+
+// GetClock returns the time on the unit's clock
+func (d *DaikinAirBase) GetClock(ctx context.Context) (time.Time, error) {
+	return d.getClock(ctx, "skyfi/common/get_datetime")
+}
+
+// SetClock sets the clock on the unit
+func (d *DaikinAirBase) SetClock(ctx context.Context, t time.Time, location *time.Location) error {
+	return d.setClock(ctx, "skyfi/common/notify_date_time", t, location)
+}
+
+// SetName renames the adapter
+func (d *DaikinAirBase) SetName(ctx context.Context, name string) error {
+	return d.setName(ctx, "skyfi/common/set_name", name)
+}
+
+// SetZoneName renames a zone, keeping the other zones' names, states and
+// temperatures
+func (d *DaikinAirBase) SetZoneName(ctx context.Context, zoneID int, name string) error {
+	if name == "" || strings.Contains(name, ";") {
+		return fmt.Errorf("invalid zone name: %q", name)
+	}
+
+	currentState, err := d.getResource(ctx, "skyfi/aircon/get_zone_setting", nil)
 	if err != nil {
+		return fmt.Errorf("failed to get current zone settings: %w", err)
+	}
+
+	names := decodeZoneList(currentState["zone_name"])
+	if zoneID < 0 || zoneID >= len(names) {
+		return fmt.Errorf("zone ID %d out of range", zoneID)
+	}
+	names[zoneID] = name
+
+	encoded := make([]string, len(names))
+	for i, zoneName := range names {
+		encoded[i] = encodeValue(zoneName)
+	}
+
+	params := map[string]string{"zone_name": strings.Join(encoded, encodeValue(";"))}
+	for _, key := range []string{"zone_onoff", "lztemp_c", "lztemp_h"} {
+		if value, exists := currentState[key]; exists {
+			params[key] = value
+		}
+	}
+
+	d.Logger.Info("Renaming zone", "zone_id", zoneID, "name", name)
+	if err := d.command(ctx, rawQuery("skyfi/aircon/set_zone_setting", params)); err != nil {
 		return fmt.Errorf("failed to set zone setting: %w", err)
 	}
 
+	currentState["zone_name"] = params["zone_name"]
+	d.Values.UpdateByResource("skyfi/aircon/get_zone_setting", currentState)
 	return nil
 }
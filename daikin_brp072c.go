@@ -11,6 +11,9 @@ type DaikinBRP072C struct {
 	*DaikinBRP069
 	Key  string
 	UUID string
+
+	// Credentials, if set, supplies the key and UUID instead of Key and UUID
+	Credentials CredentialProvider
 }
 
 // NewDaikinBRP072C creates BRP072C device
@@ -29,12 +32,16 @@ func NewDaikinBRP072C(deviceIP, key, uuid string, logger Logger) *DaikinBRP072C
 	}
 
 	brp069.Headers["X-Daikin-uuid"] = uuid
+	brp069.deviceType = "BRP072C"
 
-	return &DaikinBRP072C{
+	device := &DaikinBRP072C{
 		DaikinBRP069: brp069,
 		Key:          key,
 		UUID:         uuid,
 	}
+	brp069.prepareRequest = device.setUUIDHeader
+
+	return device
 }
 
 func (d *DaikinBRP072C) GetDeviceType() string {
@@ -42,12 +49,48 @@ func (d *DaikinBRP072C) GetDeviceType() string {
 }
 
 func (d *DaikinBRP072C) Init(ctx context.Context) error {
-	_, err := d.getResource(ctx, "common/register_terminal", map[string]string{"key": d.Key})
+	if err := d.Reauthenticate(ctx); err != nil {
+		return err
+	}
+
+	return d.DaikinBRP069.Init(ctx)
+}
+
+// Reauthenticate registers the terminal again with the current key, e.g.
+// after the key was rotated
+func (d *DaikinBRP072C) Reauthenticate(ctx context.Context) error {
+	key := d.Key
+	if d.Credentials != nil {
+		credentials, err := d.Credentials.Credentials(ctx)
+		if err != nil {
+			return NewAuthenticationError("failed to get credentials", err)
+		}
+		key = credentials.Key
+	}
+
+	_, err := d.getResource(ctx, "common/register_terminal", map[string]string{"key": key})
 	if err != nil {
 		return fmt.Errorf("failed to register terminal: %w", err)
 	}
 
-	return d.DaikinBRP069.Init(ctx)
+	return nil
+}
+
+// setUUIDHeader sends the current UUID from Credentials with every request
+func (d *DaikinBRP072C) setUUIDHeader(ctx context.Context, req *http.Request) error {
+	if d.Credentials == nil {
+		return nil
+	}
+
+	credentials, err := d.Credentials.Credentials(ctx)
+	if err != nil {
+		return NewAuthenticationError("failed to get credentials", err)
+	}
+	if credentials.UUID != "" {
+		req.Header.Set("X-Daikin-uuid", credentials.UUID)
+	}
+
+	return nil
 }
 
 // Override getResource to use the proper base appliance method
@@ -5,91 +5,78 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
+// DaikinAttribute is a value to write to the attribute tree
 type DaikinAttribute struct {
 	Name  string
-	Value interface{}
-	Path  []string
-	To    string
+	Value string
+	// Path is the chain of property names from the resource root down to
+	// the parent of the attribute
+	Path []string
+	To   string
 }
 
-func (d *DaikinAttribute) Format() map[string]interface{} {
-	return map[string]interface{}{
-		"pn": d.Name,
-		"pv": d.Value,
+// NewDaikinAttribute creates an attribute writing value at path
+func NewDaikinAttribute(path AttributePath, value string) DaikinAttribute {
+	names := path.names()
+	return DaikinAttribute{
+		Name:  names[len(names)-1],
+		Value: value,
+		Path:  names[:len(names)-1],
+		To:    path.To,
 	}
 }
 
+func (d *DaikinAttribute) Format() MultiReqProperty {
+	value, _ := json.Marshal(d.Value)
+	return MultiReqProperty{Name: d.Name, Value: value}
+}
+
 type DaikinRequest struct {
 	Attributes []DaikinAttribute
 }
 
-// Serialize the request to JSON payload
-func (d *DaikinRequest) Serialize(payload map[string]interface{}) map[string]interface{} {
+// Serialize adds the attributes to payload as write requests, merging
+// attributes that share a resource and parent properties into one tree
+func (d *DaikinRequest) Serialize(payload *MultiRequest) *MultiRequest {
 	if payload == nil {
-		payload = map[string]interface{}{
-			"requests": []map[string]interface{}{},
-		}
-	}
-
-	requests := payload["requests"].([]map[string]interface{})
-
-	getExistingTo := func(to string, requests []map[string]interface{}) map[string]interface{} {
-		for _, request := range requests {
-			if thisTo, exists := request["to"]; exists && thisTo == to {
-				return request
-			}
-		}
-		return nil
-	}
-
-	getExistingIndex := func(name string, children []map[string]interface{}) int {
-		for index, child := range children {
-			if pn, exists := child["pn"]; exists && pn == name {
-				return index
-			}
-		}
-		return -1
+		payload = &MultiRequest{Requests: []MultiReqRequest{}}
 	}
 
 	for _, attribute := range d.Attributes {
-		to := getExistingTo(attribute.To, requests)
-		if to == nil {
-			newRequest := map[string]interface{}{
-				"op": 3,
-				"pc": map[string]interface{}{
-					"pn":  "dgc_status",
-					"pch": []map[string]interface{}{},
-				},
-				"to": attribute.To,
+		index := -1
+		for i, request := range payload.Requests {
+			if request.Op == MultiReqOpWrite && request.To == attribute.To {
+				index = i
+				break
 			}
-			requests = append(requests, newRequest)
-			to = newRequest
+		}
+		if index == -1 {
+			payload.Requests = append(payload.Requests, MultiReqRequest{
+				Op: MultiReqOpWrite,
+				PC: &MultiReqProperty{Name: attribute.Path[0]},
+				To: attribute.To,
+			})
+			index = len(payload.Requests) - 1
 		}
 
-		entry := to["pc"].(map[string]interface{})["pch"].([]map[string]interface{})
-		for _, pn := range attribute.Path {
-			index := getExistingIndex(pn, entry)
-			if index == -1 {
-				newEntry := map[string]interface{}{
-					"pn":  pn,
-					"pch": []map[string]interface{}{},
-				}
-				entry = append(entry, newEntry)
-				entry = newEntry["pch"].([]map[string]interface{})
-			} else {
-				entry = entry[index]["pch"].([]map[string]interface{})
+		node := payload.Requests[index].PC
+		for _, pn := range attribute.Path[1:] {
+			child := node.child(pn)
+			if child == nil {
+				node.Children = append(node.Children, MultiReqProperty{Name: pn})
+				child = &node.Children[len(node.Children)-1]
 			}
+			node = child
 		}
-		entry = append(entry, attribute.Format())
+		node.Children = append(node.Children, attribute.Format())
 	}
 
-	payload["requests"] = requests
 	return payload
 }
 
@@ -97,6 +84,11 @@ func (d *DaikinRequest) Serialize(payload map[string]interface{}) map[string]int
 type DaikinBRP084 struct {
 	*BaseAppliance
 	URL string
+
+	// IndoorAddress and OutdoorAddress select the units behind the adapter,
+	// adr_0100 and adr_0200 by default
+	IndoorAddress  string
+	OutdoorAddress string
 }
 
 // NewDaikinBRP084 creates BRP084 device
@@ -133,10 +125,13 @@ func NewDaikinBRP084(deviceIP string, logger Logger) *DaikinBRP084 {
 
 	// Empty info resources for BRP084
 	base.InfoResources = []string{}
+	base.deviceType = "BRP084"
 
 	return &DaikinBRP084{
-		BaseAppliance: base,
-		URL:           fmt.Sprintf("%s/dsiot/multireq", base.BaseURL),
+		BaseAppliance:  base,
+		URL:            fmt.Sprintf("%s/dsiot/multireq", base.BaseURL),
+		IndoorAddress:  brp084DefaultIndoor,
+		OutdoorAddress: brp084DefaultOutdoor,
 	}
 }
 
@@ -144,193 +139,94 @@ func (d *DaikinBRP084) GetDeviceType() string {
 	return "BRP084"
 }
 
-// API paths following Python exactly
-var API_PATHS = map[string]interface{}{
-	"power": []string{
-		"/dsiot/edge/adr_0100.dgc_status",
-		"dgc_status",
-		"e_1002",
-		"e_A002",
-		"p_01",
-	},
-	"mode": []string{
-		"/dsiot/edge/adr_0100.dgc_status",
-		"dgc_status",
-		"e_1002",
-		"e_3001",
-		"p_01",
-	},
-	"indoor_temp": []string{
-		"/dsiot/edge/adr_0100.dgc_status",
-		"dgc_status",
-		"e_1002",
-		"e_A00B",
-		"p_01",
-	},
-	"indoor_humidity": []string{
-		"/dsiot/edge/adr_0100.dgc_status",
-		"dgc_status",
-		"e_1002",
-		"e_A00B",
-		"p_02",
-	},
-	"outdoor_temp": []string{
-		"/dsiot/edge/adr_0200.dgc_status",
-		"dgc_status",
-		"e_1003",
-		"e_A00D",
-		"p_01",
-	},
-	"mac_address": []string{"/dsiot/edge.adp_i", "adp_i", "mac"},
-	"temp_settings": map[string][]string{
-		"cool": {
-			"/dsiot/edge/adr_0100.dgc_status",
-			"dgc_status",
-			"e_1002",
-			"e_3001",
-			"p_02",
-		},
-		"heat": {
-			"/dsiot/edge/adr_0100.dgc_status",
-			"dgc_status",
-			"e_1002",
-			"e_3001",
-			"p_03",
-		},
-		"auto": {
-			"/dsiot/edge/adr_0100.dgc_status",
-			"dgc_status",
-			"e_1002",
-			"e_3001",
-			"p_1D",
-		},
-	},
-	"fan_settings": map[string][]string{
-		"auto": {
-			"/dsiot/edge/adr_0100.dgc_status",
-			"dgc_status",
-			"e_1002",
-			"e_3001",
-			"p_26",
-		},
-		"cool": {
-			"/dsiot/edge/adr_0100.dgc_status",
-			"dgc_status",
-			"e_1002",
-			"e_3001",
-			"p_09",
-		},
-		"heat": {
-			"/dsiot/edge/adr_0100.dgc_status",
-			"dgc_status",
-			"e_1002",
-			"e_3001",
-			"p_0A",
-		},
-		"fan": {
-			"/dsiot/edge/adr_0100.dgc_status",
-			"dgc_status",
-			"e_1002",
-			"e_3001",
-			"p_28",
-		},
-	},
-	"swing_settings": map[string]map[string][]string{
-		"auto": {
-			"vertical": {
-				"/dsiot/edge/adr_0100.dgc_status",
-				"dgc_status",
-				"e_1002",
-				"e_3001",
-				"p_20",
-			},
-			"horizontal": {
-				"/dsiot/edge/adr_0100.dgc_status",
-				"dgc_status",
-				"e_1002",
-				"e_3001",
-				"p_21",
-			},
-		},
-		"cool": {
-			"vertical": {
-				"/dsiot/edge/adr_0100.dgc_status",
-				"dgc_status",
-				"e_1002",
-				"e_3001",
-				"p_05",
-			},
-			"horizontal": {
-				"/dsiot/edge/adr_0100.dgc_status",
-				"dgc_status",
-				"e_1002",
-				"e_3001",
-				"p_06",
-			},
-		},
-		"heat": {
-			"vertical": {
-				"/dsiot/edge/adr_0100.dgc_status",
-				"dgc_status",
-				"e_1002",
-				"e_3001",
-				"p_07",
-			},
-			"horizontal": {
-				"/dsiot/edge/adr_0100.dgc_status",
-				"dgc_status",
-				"e_1002",
-				"e_3001",
-				"p_08",
-			},
-		},
-		"fan": {
-			"vertical": {
-				"/dsiot/edge/adr_0100.dgc_status",
-				"dgc_status",
-				"e_1002",
-				"e_3001",
-				"p_24",
-			},
-			"horizontal": {
-				"/dsiot/edge/adr_0100.dgc_status",
-				"dgc_status",
-				"e_1002",
-				"e_3001",
-				"p_25",
-			},
-		},
-		"dry": {
-			"vertical": {
-				"/dsiot/edge/adr_0100.dgc_status",
-				"dgc_status",
-				"e_1002",
-				"e_3001",
-				"p_22",
-			},
-			"horizontal": {
-				"/dsiot/edge/adr_0100.dgc_status",
-				"dgc_status",
-				"e_1002",
-				"e_3001",
-				"p_23",
-			},
-		},
-	},
-	"energy": map[string][]string{
-		"today_runtime": {
-			"/dsiot/edge/adr_0100.i_power.week_power",
-			"week_power",
-			"today_runtime",
-		},
-		"weekly_data": {
-			"/dsiot/edge/adr_0100.i_power.week_power",
-			"week_power",
-			"datas",
-		},
-	},
+// Resources of the attribute tree read by the driver
+const (
+	brp084IndoorStatus  = brp084Edge + brp084DefaultIndoor + ".dgc_status"
+	brp084OutdoorStatus = brp084Edge + brp084DefaultOutdoor + ".dgc_status"
+	brp084WeekPower     = brp084Edge + brp084DefaultIndoor + ".i_power.week_power"
+	brp084AdapterInfo   = "/dsiot/edge.adp_i"
+	brp084AdapterDetail = "/dsiot/edge.adp_d"
+)
+
+// brp084StatusFilter asks for values, types and metadata when reading
+const brp084StatusFilter = "?filter=pv,pt,md"
+
+// indoorAttribute returns the path of an attribute of the indoor unit status
+func indoorAttribute(section, name string) AttributePath {
+	return AttributePath{To: brp084IndoorStatus, PN: "dgc_status/e_1002/" + section + "/" + name}
+}
+
+// swingPaths are the vertical and horizontal swing attributes of a mode
+type swingPaths struct {
+	Vertical   AttributePath
+	Horizontal AttributePath
 }
 
+// Attributes modelled by the driver
+var (
+	brp084Power              = indoorAttribute("e_A002", "p_01")
+	brp084Mode               = indoorAttribute("e_3001", "p_01")
+	brp084IndoorTemperature  = indoorAttribute("e_A00B", "p_01")
+	brp084IndoorHumidity     = indoorAttribute("e_A00B", "p_02")
+	brp084OutdoorTemperature = AttributePath{To: brp084OutdoorStatus, PN: "dgc_status/e_1003/e_A00D/p_01"}
+	brp084MAC                = AttributePath{To: brp084AdapterInfo, PN: "adp_i/mac"}
+	brp084AdapterName        = AttributePath{To: brp084AdapterInfo, PN: "adp_i/name"}
+	brp084FirmwareVersion    = AttributePath{To: brp084AdapterInfo, PN: "adp_i/ver"}
+	brp084SSID               = AttributePath{To: brp084AdapterInfo, PN: "adp_i/ssid"}
+	brp084TodayRuntime       = AttributePath{To: brp084WeekPower, PN: "week_power/today_runtime"}
+	brp084WeeklyData         = AttributePath{To: brp084WeekPower, PN: "week_power/datas"}
+
+	// Cloud connection, "00" for off and "01" for on. Experimental: neither
+	// the address nor the values are confirmed by a capture from a real
+	// adapter.
+	brp084CloudConnection = AttributePath{To: brp084AdapterDetail, PN: "adp_d/cloud"}
+
+	// Fault and warning codes, their characters in hex. Experimental: not
+	// confirmed by a capture from a real adapter.
+	brp084FaultCode   = indoorAttribute("e_A003", "p_01")
+	brp084WarningCode = indoorAttribute("e_A003", "p_02")
+
+	// Wi-Fi signal (dBm) and uptime (seconds). Experimental: not confirmed
+	// by a capture from a real adapter.
+	brp084RSSI   = AttributePath{To: brp084AdapterDetail, PN: "adp_d/rssi"}
+	brp084Uptime = AttributePath{To: brp084AdapterDetail, PN: "adp_d/uptime"}
+
+	// Setpoints by mode
+	brp084TargetTemperature = map[string]AttributePath{
+		"cool": indoorAttribute("e_3001", "p_02"),
+		"heat": indoorAttribute("e_3001", "p_03"),
+		"auto": indoorAttribute("e_3001", "p_1D"),
+	}
+
+	// Fan rates by mode
+	brp084FanRate = map[string]AttributePath{
+		"auto": indoorAttribute("e_3001", "p_26"),
+		"cool": indoorAttribute("e_3001", "p_09"),
+		"heat": indoorAttribute("e_3001", "p_0A"),
+		"fan":  indoorAttribute("e_3001", "p_28"),
+	}
+
+	// Special modes, each "00" for off and "01" for on. Units that do not
+	// expose one leave it out of dgc_status. Experimental: these addresses
+	// are not confirmed by a capture from a real adapter, which is why the
+	// setters refuse to write them unless the unit reports them.
+	brp084SpecialModes = map[string]AttributePath{
+		"powerful": indoorAttribute("e_3003", "p_01"),
+		"econo":    indoorAttribute("e_3003", "p_02"),
+		"streamer": indoorAttribute("e_3003", "p_05"),
+	}
+	brp084Holiday = indoorAttribute("e_3003", "p_07")
+
+	// Swing axes by mode
+	brp084Swing = map[string]swingPaths{
+		"auto": {indoorAttribute("e_3001", "p_20"), indoorAttribute("e_3001", "p_21")},
+		"cool": {indoorAttribute("e_3001", "p_05"), indoorAttribute("e_3001", "p_06")},
+		"heat": {indoorAttribute("e_3001", "p_07"), indoorAttribute("e_3001", "p_08")},
+		"fan":  {indoorAttribute("e_3001", "p_24"), indoorAttribute("e_3001", "p_25")},
+		"dry":  {indoorAttribute("e_3001", "p_22"), indoorAttribute("e_3001", "p_23")},
+	}
+)
+
 // Mode mappings
 var MODE_MAP = map[string]string{
 	"0300": "auto",
@@ -365,176 +261,96 @@ func init() {
 const TURN_OFF_SWING_AXIS = "000000"
 const TURN_ON_SWING_AXIS = "0F0000"
 
-// Helper methods following Python exactly
-func (d *DaikinBRP084) hexToTemp(value string, divisor int) float64 {
-	if len(value) < 2 {
-		return 0
-	}
-	val, err := strconv.ParseInt(value[:2], 16, 64)
+// swingEnabled reports whether a swing axis value is on. The adapter
+// reports an active axis with either nibble of the first byte set.
+func swingEnabled(pv string) bool {
+	bits, err := decodePVBits(pv)
 	if err != nil {
-		return 0
+		return false
 	}
-	return float64(val) / float64(divisor)
+	return (bits & 0xFF).has(0x0F) || (bits & 0xFF).has(0xF0)
 }
 
-func (d *DaikinBRP084) tempToHex(temperature float64, divisor int) string {
-	return fmt.Sprintf("%02x", int(temperature*float64(divisor)))
-}
-
-func (d *DaikinBRP084) hexToInt(value string) int {
-	val, err := strconv.ParseInt(value, 16, 64)
-	if err != nil {
-		return 0
-	}
-	return int(val)
-}
-
-func (d *DaikinBRP084) getPath(keys ...string) []string {
-	current := API_PATHS
-	for _, key := range keys {
-		if next, exists := current[key]; exists {
-			switch v := next.(type) {
-			case []string:
-				return v
-			case map[string][]string:
-				current = map[string]interface{}{}
-				for k, val := range v {
-					current[k] = val
-				}
-			case map[string]map[string][]string:
-				current = map[string]interface{}{}
-				for k, val := range v {
-					current[k] = val
-				}
-			default:
-				current = next.(map[string]interface{})
-			}
-		} else {
-			d.Logger.Warn("Path key not found", "key", key)
-			return nil
-		}
+func (d *DaikinBRP084) getSwingState(response *MultiResponse) string {
+	mode, _ := d.Values.Get("mode")
+	if mode == "" || mode == "off" {
+		return "off"
 	}
-	return nil
-}
 
-func (d *DaikinBRP084) findValueByPN(data map[string]interface{}, fr string, keys ...string) (interface{}, error) {
-	responses, exists := data["responses"].([]interface{})
+	paths, exists := brp084Swing[mode]
 	if !exists {
-		return nil, fmt.Errorf("no responses found")
-	}
-
-	var targetData []interface{}
-	for _, resp := range responses {
-		if respMap, ok := resp.(map[string]interface{}); ok {
-			if respMap["fr"] == fr {
-				if pc, exists := respMap["pc"].(map[string]interface{}); exists {
-					targetData = []interface{}{pc}
-					break
-				}
-			}
-		}
+		return "off"
 	}
 
-	if len(targetData) == 0 {
-		return nil, fmt.Errorf("fr %s not found", fr)
+	vertical, err1 := d.readHex(response, paths.Vertical)
+	horizontal, err2 := d.readHex(response, paths.Horizontal)
+	if err1 != nil || err2 != nil {
+		return "off"
 	}
 
-	for _, key := range keys {
-		found := false
-		for _, dataItem := range targetData {
-			if dataMap, ok := dataItem.(map[string]interface{}); ok {
-				if dataMap["pn"] == key {
-					if len(keys) == 1 {
-						return dataMap["pv"], nil
-					}
-					if pch, exists := dataMap["pch"].([]interface{}); exists {
-						targetData = pch
-						found = true
-						break
-					}
-				}
-			}
-		}
-		if !found {
-			return nil, fmt.Errorf("key %s not found", key)
-		}
-		keys = keys[1:]
+	switch {
+	case swingEnabled(vertical) && swingEnabled(horizontal):
+		return "both"
+	case swingEnabled(horizontal):
+		return "horizontal"
+	case swingEnabled(vertical):
+		return "vertical"
 	}
-
-	return nil, fmt.Errorf("value not found")
+	return "off"
 }
 
-func (d *DaikinBRP084) getSwingState(data map[string]interface{}) string {
-	mode, _ := d.Values.Get("mode")
-	if mode == "" || mode == "off" {
-		return "off"
-	}
-
-	swingSettings := API_PATHS["swing_settings"].(map[string]map[string][]string)
-	if modeSettings, exists := swingSettings[mode]; exists {
-		verticalPath := modeSettings["vertical"]
-		horizontalPath := modeSettings["horizontal"]
-
-		verticalVal, err1 := d.findValueByPN(data, verticalPath[0], verticalPath[1:]...)
-		horizontalVal, err2 := d.findValueByPN(data, horizontalPath[0], horizontalPath[1:]...)
-
-		if err1 == nil && err2 == nil {
-			verticalStr := fmt.Sprintf("%v", verticalVal)
-			horizontalStr := fmt.Sprintf("%v", horizontalVal)
-
-			vertical := len(verticalStr) > 0 && verticalStr[0] == 'F'
-			horizontal := len(horizontalStr) > 0 && horizontalStr[0] == 'F'
-
-			if horizontal && vertical {
-				return "both"
-			}
-			if horizontal {
-				return "horizontal"
-			}
-			if vertical {
-				return "vertical"
-			}
-		}
+// readHex returns the hex value of the attribute at path
+func (d *DaikinBRP084) readHex(response *MultiResponse, path AttributePath) (string, error) {
+	property, err := response.Find(d.resolve(path))
+	if err != nil {
+		return "", err
 	}
-
-	return "off"
+	return property.Hex()
 }
 
 func (d *DaikinBRP084) Init(ctx context.Context) error {
 	return d.UpdateStatus(ctx)
 }
 
-func (d *DaikinBRP084) UpdateStatus(ctx context.Context) error {
-	payload := map[string]interface{}{
-		"requests": []map[string]interface{}{
-			{"op": 2, "to": "/dsiot/edge/adr_0100.dgc_status?filter=pv,pt,md"},
-			{"op": 2, "to": "/dsiot/edge/adr_0200.dgc_status?filter=pv,pt,md"},
-			{"op": 2, "to": "/dsiot/edge/adr_0100.i_power.week_power?filter=pv,pt,md"},
-			{"op": 2, "to": "/dsiot/edge.adp_i"},
-		},
-	}
+func (d *DaikinBRP084) UpdateStatus(ctx context.Context) (err error) {
+	ctx, span := d.startSpan(ctx, "daikin.update_status")
+	defer func() { endSpan(span, err) }()
 
-	response, err := d.getResource(ctx, "", payload)
+	request := newReadRequest(
+		d.resource(brp084IndoorStatus)+brp084StatusFilter,
+		d.resource(brp084OutdoorStatus)+brp084StatusFilter,
+		d.resource(brp084WeekPower)+brp084StatusFilter,
+		brp084AdapterInfo,
+	)
+
+	response, err := d.multiRequest(ctx, request)
 	if err != nil {
 		return fmt.Errorf("failed to communicate with device: %w", err)
 	}
 
-	responseMap, ok := response.(map[string]interface{})
-	if !ok || responseMap["responses"] == nil {
+	if len(response.Responses) == 0 {
 		return fmt.Errorf("invalid response from device")
 	}
 
 	// Extract basic info
-	macPath := d.getPath("mac_address")
-	if mac, err := d.findValueByPN(responseMap, macPath[0], macPath[1:]...); err == nil {
-		d.Values.Set("mac", fmt.Sprintf("%v", mac))
+	if mac, err := d.readHex(response, brp084MAC); err == nil {
+		d.Values.Set("mac", mac)
+	}
+	for key, path := range map[string]AttributePath{
+		"name": brp084AdapterName,
+		"ver":  brp084FirmwareVersion,
+		"ssid": brp084SSID,
+	} {
+		if property, err := response.Find(path); err == nil {
+			if text, err := property.Text(); err == nil {
+				d.Values.Set(key, text)
+			}
+		}
 	}
 
 	// Get power state
-	powerPath := d.getPath("power")
-	if powerVal, err := d.findValueByPN(responseMap, powerPath[0], powerPath[1:]...); err == nil {
-		isOff := fmt.Sprintf("%v", powerVal) == "00"
-		if isOff {
+	if power, err := d.readHex(response, brp084Power); err == nil {
+		if power == "00" {
 			d.Values.Set("pow", "0")
 		} else {
 			d.Values.Set("pow", "1")
@@ -542,45 +358,43 @@ func (d *DaikinBRP084) UpdateStatus(ctx context.Context) error {
 	}
 
 	// Get mode
-	modePath := d.getPath("mode")
-	if modeVal, err := d.findValueByPN(responseMap, modePath[0], modePath[1:]...); err == nil {
-		modeStr := fmt.Sprintf("%v", modeVal)
+	if mode, err := d.readHex(response, brp084Mode); err == nil {
 		if pow, _ := d.Values.Get("pow"); pow == "0" {
 			d.Values.Set("mode", "off")
-		} else if humanMode, exists := MODE_MAP[modeStr]; exists {
+		} else if humanMode, exists := MODE_MAP[mode]; exists {
 			d.Values.Set("mode", humanMode)
 		}
 	}
 
 	// Get temperatures
-	otempPath := d.getPath("outdoor_temp")
-	if otempVal, err := d.findValueByPN(responseMap, otempPath[0], otempPath[1:]...); err == nil {
-		otemp := d.hexToTemp(fmt.Sprintf("%v", otempVal), 2)
-		d.Values.Set("otemp", fmt.Sprintf("%.1f", otemp))
+	if pv, err := d.readHex(response, brp084OutdoorTemperature); err == nil {
+		if otemp, err := decodePVTemperature(pv, 2); err == nil {
+			d.Values.Set("otemp", fmt.Sprintf("%.1f", otemp))
+		}
 	}
 
-	htempPath := d.getPath("indoor_temp")
-	if htempVal, err := d.findValueByPN(responseMap, htempPath[0], htempPath[1:]...); err == nil {
-		htemp := d.hexToTemp(fmt.Sprintf("%v", htempVal), 1)
-		d.Values.Set("htemp", fmt.Sprintf("%.1f", htemp))
+	if pv, err := d.readHex(response, brp084IndoorTemperature); err == nil {
+		if htemp, err := decodePVTemperature(pv, 1); err == nil {
+			d.Values.Set("htemp", fmt.Sprintf("%.1f", htemp))
+		}
 	}
 
 	// Get humidity
-	humidPath := d.getPath("indoor_humidity")
-	if humidVal, err := d.findValueByPN(responseMap, humidPath[0], humidPath[1:]...); err == nil {
-		humid := d.hexToInt(fmt.Sprintf("%v", humidVal))
-		d.Values.Set("hhum", fmt.Sprintf("%d", humid))
-	} else {
-		d.Values.Set("hhum", "--")
+	hhum := "--"
+	if pv, err := d.readHex(response, brp084IndoorHumidity); err == nil {
+		if humidity, err := decodePVUint(pv); err == nil {
+			hhum = strconv.FormatUint(humidity, 10)
+		}
 	}
+	d.Values.Set("hhum", hhum)
 
 	// Get target temperature
 	if mode, _ := d.Values.Get("mode"); mode != "" && mode != "off" {
-		tempSettings := API_PATHS["temp_settings"].(map[string][]string)
-		if tempPath, exists := tempSettings[mode]; exists {
-			if stempVal, err := d.findValueByPN(responseMap, tempPath[0], tempPath[1:]...); err == nil {
-				stemp := d.hexToTemp(fmt.Sprintf("%v", stempVal), 2)
-				d.Values.Set("stemp", fmt.Sprintf("%.1f", stemp))
+		if path, exists := brp084TargetTemperature[mode]; exists {
+			if pv, err := d.readHex(response, path); err == nil {
+				if stemp, err := decodePVTemperature(pv, 2); err == nil {
+					d.Values.Set("stemp", fmt.Sprintf("%.1f", stemp))
+				}
 			}
 		}
 	} else {
@@ -589,11 +403,9 @@ func (d *DaikinBRP084) UpdateStatus(ctx context.Context) error {
 
 	// Get fan mode
 	if mode, _ := d.Values.Get("mode"); mode != "" && mode != "off" {
-		fanSettings := API_PATHS["fan_settings"].(map[string][]string)
-		if fanPath, exists := fanSettings[mode]; exists {
-			if fanVal, err := d.findValueByPN(responseMap, fanPath[0], fanPath[1:]...); err == nil {
-				fanStr := fmt.Sprintf("%v", fanVal)
-				if humanFan, exists := FAN_MODE_MAP[fanStr]; exists {
+		if path, exists := brp084FanRate[mode]; exists {
+			if pv, err := d.readHex(response, path); err == nil {
+				if humanFan, exists := FAN_MODE_MAP[pv]; exists {
 					d.Values.Set("f_rate", humanFan)
 				} else {
 					d.Values.Set("f_rate", "auto")
@@ -605,46 +417,55 @@ func (d *DaikinBRP084) UpdateStatus(ctx context.Context) error {
 	}
 
 	// Get swing mode
-	d.Values.Set("f_dir", d.getSwingState(responseMap))
+	d.Values.Set("f_dir", d.getSwingState(response))
+
+	// Get fault codes, left unset by units that do not report them
+	for key, path := range map[string]AttributePath{"err": brp084FaultCode, "warn": brp084WarningCode} {
+		if pv, err := d.readHex(response, path); err == nil {
+			d.Values.Set(key, decodeFaultCode(pv))
+		}
+	}
+
+	// Get the setpoints remembered for every mode
+	d.updateModeSetpoints(response)
+
+	// Get special modes
+	d.updateSpecialModes(response)
 
 	// Get energy data
-	energyPaths := API_PATHS["energy"].(map[string][]string)
-	if runtimePath, exists := energyPaths["today_runtime"]; exists {
-		if runtimeVal, err := d.findValueByPN(responseMap, runtimePath[0], runtimePath[1:]...); err == nil {
-			d.Values.Set("today_runtime", fmt.Sprintf("%v", runtimeVal))
+	if property, err := response.Find(d.resolve(brp084TodayRuntime)); err == nil {
+		if runtime, err := property.Text(); err == nil {
+			d.Values.Set("today_runtime", runtime)
 		}
 	}
 
-	if weeklyPath, exists := energyPaths["weekly_data"]; exists {
-		if weeklyVal, err := d.findValueByPN(responseMap, weeklyPath[0], weeklyPath[1:]...); err == nil {
-			if weeklyList, ok := weeklyVal.([]interface{}); ok && len(weeklyList) > 0 {
-				var strs []string
-				for _, v := range weeklyList {
-					strs = append(strs, fmt.Sprintf("%v", v))
-				}
-				d.Values.Set("datas", strings.Join(strs, "/"))
-			}
+	if property, err := response.Find(d.resolve(brp084WeeklyData)); err == nil {
+		if weekly, err := property.List(); err == nil && len(weekly) > 0 {
+			d.Values.Set("datas", strings.Join(weekly, "/"))
 		}
 	}
 
 	return nil
 }
 
-func (d *DaikinBRP084) getResource(ctx context.Context, path string, params interface{}) (interface{}, error) {
-	d.Logger.Debug("Making BRP084 request", "url", d.URL, "path", path, "params", params)
+// multiRequest posts a multireq and decodes its response
+func (d *DaikinBRP084) multiRequest(ctx context.Context, request *MultiRequest) (response *MultiResponse, err error) {
+	ctx, span := d.startSpan(ctx, "daikin.get_resource",
+		attrResourcePath.String("dsiot/multireq"), attrTargets.StringSlice(request.targets()))
+	defer func() { endSpan(span, err) }()
 
-	var jsonData []byte
-	var err error
-	if params != nil {
-		jsonData, err = json.Marshal(params)
-		if err != nil {
-			return nil, err
-		}
+	d.Logger.Debug("Making BRP084 request", "url", d.URL, "request", request)
+	start := time.Now()
+	defer func() { d.health.record(time.Since(start), err) }()
+
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return nil, NewDaikinError("failed to encode request", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", d.URL, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return nil, err
+		return nil, NewConnectionError("failed to create request", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -654,44 +475,47 @@ func (d *DaikinBRP084) getResource(ctx context.Context, path string, params inte
 
 	resp, err := d.HTTPClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, NewConnectionError("failed to make request", err)
 	}
 	defer resp.Body.Close()
 
+	span.SetAttributes(attrHTTPStatus.Int(resp.StatusCode))
+
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected HTTP status: %d", resp.StatusCode)
+		return nil, NewConnectionError(fmt.Sprintf("unexpected HTTP status: %d", resp.StatusCode), nil)
 	}
 
-	var result interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	body, err := readBody(resp.Body, d.MaxResponseSize)
+	if err != nil {
 		return nil, err
 	}
 
-	return result, nil
-}
+	response = &MultiResponse{}
+	if err := json.Unmarshal(body, response); err != nil {
+		return nil, NewParseError("invalid JSON response", err)
+	}
 
-func (d *DaikinBRP084) addRequest(requests *[]DaikinAttribute, path []string, value string) {
-	attr := DaikinAttribute{
-		Name:  path[len(path)-1],
-		Value: value,
-		Path:  path[2:4],
-		To:    path[0],
+	if rscs := response.responseCodes(); len(rscs) > 0 {
+		span.SetAttributes(attrRsc.IntSlice(rscs))
 	}
-	*requests = append(*requests, attr)
+
+	return response, nil
+}
+
+func (d *DaikinBRP084) addRequest(requests *[]DaikinAttribute, path AttributePath, value string) {
+	*requests = append(*requests, NewDaikinAttribute(d.resolve(path), value))
 }
 
 func (d *DaikinBRP084) handlePowerSetting(settings map[string]string, requests *[]DaikinAttribute) {
 	if mode, exists := settings["mode"]; exists {
-		powerPath := d.getPath("power")
 		if mode == "off" {
-			d.addRequest(requests, powerPath, "00")
+			d.addRequest(requests, brp084Power, "00")
 		} else {
-			d.addRequest(requests, powerPath, "01")
+			d.addRequest(requests, brp084Power, "01")
 
 			// Set mode
 			if modeValue, exists := REVERSE_MODE_MAP[mode]; exists {
-				modePath := d.getPath("mode")
-				d.addRequest(requests, modePath, modeValue)
+				d.addRequest(requests, brp084Mode, modeValue)
 			}
 		}
 	}
@@ -700,11 +524,9 @@ func (d *DaikinBRP084) handlePowerSetting(settings map[string]string, requests *
 func (d *DaikinBRP084) handleTemperatureSetting(settings map[string]string, requests *[]DaikinAttribute) {
 	if stemp, exists := settings["stemp"]; exists {
 		if mode, _ := d.Values.Get("mode"); mode != "" {
-			tempSettings := API_PATHS["temp_settings"].(map[string][]string)
-			if tempPath, exists := tempSettings[mode]; exists {
+			if path, exists := brp084TargetTemperature[mode]; exists {
 				temp, _ := strconv.ParseFloat(stemp, 64)
-				tempHex := d.tempToHex(temp, 2)
-				d.addRequest(requests, tempPath, tempHex)
+				d.addRequest(requests, path, encodePVTemperature(temp, 2, 1))
 			}
 		}
 	}
@@ -713,10 +535,9 @@ func (d *DaikinBRP084) handleTemperatureSetting(settings map[string]string, requ
 func (d *DaikinBRP084) handleFanSetting(settings map[string]string, requests *[]DaikinAttribute) {
 	if fRate, exists := settings["f_rate"]; exists {
 		if mode, _ := d.Values.Get("mode"); mode != "" {
-			fanSettings := API_PATHS["fan_settings"].(map[string][]string)
-			if fanPath, exists := fanSettings[mode]; exists {
+			if path, exists := brp084FanRate[mode]; exists {
 				if fanValue, exists := REVERSE_FAN_MODE_MAP[fRate]; exists {
-					d.addRequest(requests, fanPath, fanValue)
+					d.addRequest(requests, path, fanValue)
 				}
 			}
 		}
@@ -726,11 +547,7 @@ func (d *DaikinBRP084) handleFanSetting(settings map[string]string, requests *[]
 func (d *DaikinBRP084) handleSwingSetting(settings map[string]string, requests *[]DaikinAttribute) {
 	if fDir, exists := settings["f_dir"]; exists {
 		if mode, _ := d.Values.Get("mode"); mode != "" {
-			swingSettings := API_PATHS["swing_settings"].(map[string]map[string][]string)
-			if modeSettings, exists := swingSettings[mode]; exists {
-				verticalPath := modeSettings["vertical"]
-				horizontalPath := modeSettings["horizontal"]
-
+			if paths, exists := brp084Swing[mode]; exists {
 				var verticalValue, horizontalValue string
 				switch fDir {
 				case "off":
@@ -747,15 +564,15 @@ func (d *DaikinBRP084) handleSwingSetting(settings map[string]string, requests *
 					horizontalValue = TURN_ON_SWING_AXIS
 				}
 
-				d.addRequest(requests, verticalPath, verticalValue)
-				d.addRequest(requests, horizontalPath, horizontalValue)
+				d.addRequest(requests, paths.Vertical, verticalValue)
+				d.addRequest(requests, paths.Horizontal, horizontalValue)
 			}
 		}
 	}
 }
 
 func (d *DaikinBRP084) updateSettings(_ context.Context, settings map[string]string) error {
-	log.Printf("Updating settings: %v", settings)
+	d.Logger.Debug("Updating settings", "settings", settings)
 
 	for key, value := range settings {
 		if key == "mode" && value == "off" {
@@ -771,8 +588,11 @@ func (d *DaikinBRP084) updateSettings(_ context.Context, settings map[string]str
 	return nil
 }
 
-func (d *DaikinBRP084) Set(ctx context.Context, settings map[string]string) error {
-	err := d.updateSettings(ctx, settings)
+func (d *DaikinBRP084) Set(ctx context.Context, settings map[string]string) (err error) {
+	ctx, span := d.startSpan(ctx, "daikin.set")
+	defer func() { endSpan(span, err) }()
+
+	err = d.updateSettings(ctx, settings)
 	if err != nil {
 		return err
 	}
@@ -785,15 +605,9 @@ func (d *DaikinBRP084) Set(ctx context.Context, settings map[string]string) erro
 	d.handleSwingSetting(settings, &requests)
 
 	if len(requests) > 0 {
-		request := DaikinRequest{Attributes: requests}
-		requestPayload := request.Serialize(nil)
-		log.Printf("Sending request: %v", requestPayload)
-
-		response, err := d.getResource(ctx, "", requestPayload)
-		if err != nil {
+		if err := d.write(ctx, requests); err != nil {
 			return err
 		}
-		log.Printf("Response: %v", response)
 
 		// Update status after setting
 		return d.UpdateStatus(ctx)
@@ -802,31 +616,419 @@ func (d *DaikinBRP084) Set(ctx context.Context, settings map[string]string) erro
 	return nil
 }
 
-// SetStreamer - not supported in firmware 2.8.0
+// updateSpecialModes sets 'adv' from the special modes that are on, in the
+// form used by the BRP069 translations ("powerful streamer"), and a switch
+// per reported mode (en_powerful, en_econo, en_streamer) and for holiday
+func (d *DaikinBRP084) updateSpecialModes(response *MultiResponse) {
+	var active []string
+	found := false
+	for _, mode := range []string{"powerful", "econo", "streamer"} {
+		pv, err := d.readHex(response, brp084SpecialModes[mode])
+		if err != nil {
+			continue
+		}
+		found = true
+		if pv != "00" {
+			active = append(active, mode)
+		}
+		d.Values.Set("en_"+mode, pvSwitch(pv))
+	}
+
+	if found {
+		adv := strings.Join(active, " ")
+		if adv == "" {
+			adv = "off"
+		}
+		d.Values.Set("adv", adv)
+	}
+
+	if pv, err := d.readHex(response, brp084Holiday); err == nil {
+		d.Values.Set("en_hol", pvSwitch(pv))
+	}
+}
+
+// pvSwitch converts an on/off attribute value to "0" or "1"
+func pvSwitch(pv string) string {
+	if pv == "00" {
+		return "0"
+	}
+	return "1"
+}
+
+// switchValue converts "on" or "off" to an attribute value
+func switchValue(value string) (string, bool) {
+	switch value {
+	case "on", "1":
+		return "01", true
+	case "off", "0":
+		return "00", true
+	}
+	return "", false
+}
+
+// Info returns the adapter information from the last status update. Unlike
+// the key=value adapters, adp_i holds the SSID as plain text.
+func (d *DaikinBRP084) Info() DeviceInfo {
+	info := d.BaseAppliance.Info()
+	info.SSID, _ = d.Values.Get("ssid")
+	return info
+}
+
+// SupportsFaults reports whether the unit reported fault codes in its last
+// status update. Experimental, see brp084FaultCode.
+func (d *DaikinBRP084) SupportsFaults() bool {
+	return d.supportsFaults()
+}
+
+// Faults returns the fault and warning codes of the last status update
+func (d *DaikinBRP084) Faults() ([]Fault, error) {
+	return d.faults()
+}
+
+// SupportsRemoteSettings returns false once a read found no cloud
+// connection setting, which older firmwares lack. The setting is read
+// lazily, by the first GetRemoteSettings or SetRemoteSettings.
+// Experimental, see brp084CloudConnection.
+func (d *DaikinBRP084) SupportsRemoteSettings() bool {
+	value, read := d.Values.Get("cloud")
+	return !read || value != ""
+}
+
+// GetRemoteSettings returns the cloud connection setting. The adapter has
+// no intervals, so only Method is set.
+func (d *DaikinBRP084) GetRemoteSettings(ctx context.Context) (*RemoteSettings, error) {
+	attributes, err := d.ReadAttributes(ctx, brp084CloudConnection)
+	if err != nil {
+		return nil, err
+	}
+	property, exists := attributes[brp084CloudConnection]
+	if !exists {
+		// Remembered as empty so SupportsRemoteSettings turns false
+		d.Values.Set("cloud", "")
+		return nil, NewCapabilityError("cloud connection setting not supported by this adapter", nil)
+	}
+	value, err := property.Hex()
+	if err != nil {
+		return nil, err
+	}
+
+	d.Values.Set("cloud", pvSwitch(value))
+	if value == "00" {
+		return &RemoteSettings{Method: RemoteHomeOnly}, nil
+	}
+	return &RemoteSettings{Method: RemotePolling}, nil
+}
+
+// SetRemoteSettings turns the cloud connection on for RemotePolling and off
+// for RemoteHomeOnly; intervals are ignored
+func (d *DaikinBRP084) SetRemoteSettings(ctx context.Context, settings RemoteSettings) error {
+	if err := settings.validate(); err != nil {
+		return err
+	}
+	if !d.Values.Has("cloud") {
+		if _, err := d.GetRemoteSettings(ctx); err != nil {
+			return err
+		}
+	}
+	if !d.SupportsRemoteSettings() {
+		return NewCapabilityError("cloud connection setting not supported by this adapter", nil)
+	}
+
+	value := "01"
+	if settings.Method == RemoteHomeOnly {
+		value = "00"
+	}
+
+	d.Logger.Info("Setting cloud connection", "method", settings.Method)
+	if err := d.write(ctx, []DaikinAttribute{NewDaikinAttribute(brp084CloudConnection, value)}); err != nil {
+		return fmt.Errorf("failed to set cloud connection: %w", err)
+	}
+	d.Values.Set("cloud", pvSwitch(value))
+	return nil
+}
+
+// Health adds the Wi-Fi signal and uptime from adp_d, experimental, to the
+// figures of recent requests, which leave out this read. The signal is nil
+// if the read fails.
+func (d *DaikinBRP084) Health(ctx context.Context) (*AdapterHealth, error) {
+	health, err := d.BaseAppliance.Health(ctx)
+	if err != nil {
+		return nil, err
+	}
+	attributes, _ := d.ReadAttributes(ctx, brp084RSSI, brp084Uptime)
+
+	var rssi *int
+	if property, exists := attributes[brp084RSSI]; exists {
+		if text, err := property.Text(); err == nil {
+			if value, err := strconv.Atoi(text); err == nil {
+				rssi = &value
+			}
+		}
+	}
+	d.health.setRSSI(rssi)
+	health.RSSI = rssi
+
+	if property, exists := attributes[brp084Uptime]; exists {
+		if text, err := property.Text(); err == nil {
+			if seconds, err := strconv.Atoi(text); err == nil {
+				health.Uptime = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return health, nil
+}
+
+// SetStreamer turns the streamer on or off. Experimental, see
+// brp084SpecialModes.
 func (d *DaikinBRP084) SetStreamer(ctx context.Context, mode string) error {
-	log.Printf("Streamer mode not supported in firmware 2.8.0")
-	return fmt.Errorf("streamer mode not supported in firmware 2.8.0")
+	return d.SetAdvancedMode(ctx, "streamer", mode)
 }
 
-// SetHoliday - not supported in firmware 2.8.0
+// SetHoliday turns holiday (away) mode on or off. Experimental, see
+// brp084SpecialModes.
 func (d *DaikinBRP084) SetHoliday(ctx context.Context, mode string) error {
-	log.Printf("Holiday mode not supported in firmware 2.8.0")
-	return fmt.Errorf("holiday mode not supported in firmware 2.8.0")
+	value, ok := switchValue(mode)
+	if !ok {
+		return fmt.Errorf("invalid holiday mode: %s", mode)
+	}
+	if !d.SupportsAwayMode() {
+		return NewCapabilityError("holiday mode not supported by this unit", nil)
+	}
+
+	d.Logger.Info("Setting holiday mode", "mode", mode)
+	if err := d.write(ctx, []DaikinAttribute{NewDaikinAttribute(d.resolve(brp084Holiday), value)}); err != nil {
+		return fmt.Errorf("failed to set holiday mode: %w", err)
+	}
+
+	return d.UpdateStatus(ctx)
 }
 
-// SetAdvancedMode - not supported in firmware 2.8.0
+// SetAdvancedMode turns the powerful, econo or streamer mode on or off,
+// returning a CapabilityError if the unit does not report that mode.
+// Experimental, see brp084SpecialModes.
 func (d *DaikinBRP084) SetAdvancedMode(ctx context.Context, mode, value string) error {
-	log.Printf("Advanced mode not supported in firmware 2.8.0")
-	return fmt.Errorf("advanced mode not supported in firmware 2.8.0")
+	path, exists := brp084SpecialModes[mode]
+	if !exists {
+		return fmt.Errorf("invalid advanced mode: %s", mode)
+	}
+	pv, ok := switchValue(value)
+	if !ok {
+		return fmt.Errorf("invalid advanced mode value: %s", value)
+	}
+	if !d.Values.Has("en_" + mode) {
+		return NewCapabilityError(fmt.Sprintf("%s mode not supported by this unit", mode), nil)
+	}
+
+	d.Logger.Info("Setting advanced mode", "mode", mode, "value", value)
+	if err := d.write(ctx, []DaikinAttribute{NewDaikinAttribute(d.resolve(path), pv)}); err != nil {
+		return fmt.Errorf("failed to set advanced mode: %w", err)
+	}
+
+	return d.UpdateStatus(ctx)
+}
+
+// brp084Modes are the modes with setpoint memory
+var brp084Modes = []string{"auto", "cool", "heat", "dry", "fan"}
+
+// updateModeSetpoints stores the setpoints of every mode as dt_<mode> and
+// dfr_<mode>
+func (d *DaikinBRP084) updateModeSetpoints(response *MultiResponse) {
+	for _, mode := range brp084Modes {
+		if path, exists := brp084TargetTemperature[mode]; exists {
+			if pv, err := d.readHex(response, path); err == nil {
+				if temperature, err := decodePVTemperature(pv, 2); err == nil {
+					d.Values.Set("dt_"+mode, fmt.Sprintf("%.1f", temperature))
+				}
+			}
+		}
+		if path, exists := brp084FanRate[mode]; exists {
+			if pv, err := d.readHex(response, path); err == nil {
+				if fanRate, exists := FAN_MODE_MAP[pv]; exists {
+					d.Values.Set("dfr_"+mode, fanRate)
+				}
+			}
+		}
+		if paths, exists := brp084Swing[mode]; exists {
+			if pv, err := d.readHex(response, paths.Vertical); err == nil {
+				d.Values.Set("f_dir_ud_"+mode, string(decodeLouverPosition(pv)))
+			}
+			if pv, err := d.readHex(response, paths.Horizontal); err == nil {
+				d.Values.Set("f_dir_lr_"+mode, string(decodeLouverPosition(pv)))
+			}
+		}
+	}
+}
+
+// Louver axis values: the first byte holds the state. Swing ("0F0000") and
+// stopped ("000000") are the values the driver has always sent; comfort and
+// the fixed positions 1 to 5 are not confirmed by a capture and are
+// experimental.
+const (
+	brp084LouverStopped = 0x00
+	brp084LouverSwing   = 0x0F
+	brp084LouverComfort = 0x14
+)
+
+func decodeLouverPosition(pv string) LouverPosition {
+	if swingEnabled(pv) {
+		return LouverSwing
+	}
+	bits, err := decodePVBits(pv)
+	if err != nil {
+		return LouverStopped
+	}
+	switch state := int(bits & 0xFF); {
+	case state == brp084LouverComfort:
+		return LouverComfort
+	case state >= MinLouverPosition && state <= MaxLouverPosition:
+		return LouverFixed(state)
+	}
+	return LouverStopped
+}
+
+func encodeLouverPosition(position LouverPosition) string {
+	state := brp084LouverStopped
+	switch position {
+	case LouverSwing:
+		state = brp084LouverSwing
+	case LouverComfort:
+		state = brp084LouverComfort
+	default:
+		if n, ok := position.Fixed(); ok {
+			state = n
+		}
+	}
+	return encodePVUint(uint64(state), 3)
+}
+
+// SupportsLouverControl returns whether the unit reports louver settings
+func (d *DaikinBRP084) SupportsLouverControl() bool {
+	for _, mode := range brp084Modes {
+		if d.Values.Has("f_dir_ud_" + mode) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetLouverSettings returns the louver settings of a mode
+func (d *DaikinBRP084) GetLouverSettings(mode string) (LouverSettings, error) {
+	vertical, hasVertical := d.Values.Get("f_dir_ud_" + mode)
+	horizontal, hasHorizontal := d.Values.Get("f_dir_lr_" + mode)
+	if !hasVertical || !hasHorizontal {
+		return LouverSettings{}, NewCapabilityError(fmt.Sprintf("no louver settings for mode: %s", mode), nil)
+	}
+	return LouverSettings{Vertical: LouverPosition(vertical), Horizontal: LouverPosition(horizontal)}, nil
+}
+
+// SetLouverSettings sets the louver settings of a mode. Empty fields are
+// left unchanged. LouverComfort and fixed positions are experimental on
+// BRP084 adapters.
+func (d *DaikinBRP084) SetLouverSettings(ctx context.Context, mode string, settings LouverSettings) error {
+	paths, exists := brp084Swing[mode]
+	if !exists {
+		return NewCapabilityError(fmt.Sprintf("no louver settings for mode: %s", mode), nil)
+	}
+
+	var attributes []DaikinAttribute
+	for _, axis := range []struct {
+		position LouverPosition
+		path     AttributePath
+	}{{settings.Vertical, paths.Vertical}, {settings.Horizontal, paths.Horizontal}} {
+		if axis.position == "" {
+			continue
+		}
+		if err := axis.position.validate(); err != nil {
+			return err
+		}
+		d.addRequest(&attributes, axis.path, encodeLouverPosition(axis.position))
+	}
+
+	if len(attributes) == 0 {
+		return nil
+	}
+
+	d.Logger.Info("Setting louver settings", "mode", mode, "settings", settings)
+	if err := d.write(ctx, attributes); err != nil {
+		return fmt.Errorf("failed to set louver settings: %w", err)
+	}
+
+	return d.UpdateStatus(ctx)
+}
+
+// GetModeSetpoints returns the setpoints remembered for each mode
+func (d *DaikinBRP084) GetModeSetpoints() map[string]ModeSetpoint {
+	values := d.Values.All()
+	setpoints := make(map[string]ModeSetpoint)
+	for _, mode := range brp084Modes {
+		setpoint := ModeSetpoint{
+			Temperature: values["dt_"+mode],
+			FanRate:     values["dfr_"+mode],
+		}
+		if setpoint != (ModeSetpoint{}) {
+			setpoints[mode] = setpoint
+		}
+	}
+	return setpoints
+}
+
+// SetModeSetpoint changes the setpoint remembered for a mode by writing
+// that mode's attributes, without switching the unit into it
+func (d *DaikinBRP084) SetModeSetpoint(ctx context.Context, mode string, setpoint ModeSetpoint) error {
+	var attributes []DaikinAttribute
+
+	if setpoint.Temperature != "" {
+		path, exists := brp084TargetTemperature[mode]
+		if !exists {
+			return fmt.Errorf("no temperature setpoint for mode: %s", mode)
+		}
+		temperature, err := strconv.ParseFloat(setpoint.Temperature, 64)
+		if err != nil {
+			return fmt.Errorf("invalid temperature: %s", setpoint.Temperature)
+		}
+		d.addRequest(&attributes, path, encodePVTemperature(temperature, 2, 1))
+	}
+
+	if setpoint.Humidity != "" {
+		return NewCapabilityError("humidity setpoints not supported by BRP084 adapters", nil)
+	}
+
+	if setpoint.FanRate != "" {
+		path, exists := brp084FanRate[mode]
+		if !exists {
+			return fmt.Errorf("no fan rate setpoint for mode: %s", mode)
+		}
+		fanValue, exists := REVERSE_FAN_MODE_MAP[setpoint.FanRate]
+		if !exists {
+			return fmt.Errorf("invalid fan rate: %s", setpoint.FanRate)
+		}
+		d.addRequest(&attributes, path, fanValue)
+	}
+
+	if len(attributes) == 0 {
+		return nil
+	}
+
+	d.Logger.Info("Setting mode setpoint", "mode", mode, "setpoint", setpoint)
+	if err := d.write(ctx, attributes); err != nil {
+		return fmt.Errorf("failed to set mode setpoint: %w", err)
+	}
+
+	return d.UpdateStatus(ctx)
+}
+
+// GetHumidity returns the current humidity
+func (d *DaikinBRP084) GetHumidity() (float64, error) {
+	return d.parseFloat("hhum")
 }
 
 // Support properties like Python
 func (d *DaikinBRP084) SupportAwayMode() bool {
-	return false // not supported in firmware 2.8.0
+	return d.SupportsAwayMode()
 }
 
 func (d *DaikinBRP084) SupportAdvancedModes() bool {
-	return false // not supported in firmware 2.8.0
+	return d.SupportsAdvancedModes()
 }
 
 func (d *DaikinBRP084) SupportZoneCount() bool {
@@ -913,6 +1115,23 @@ func (d *DaikinBRP084) SupportsEnergyConsumption() bool {
 	return d.Values.Has("datas") || d.Values.Has("today_runtime")
 }
 
+// GetEnergyHistory returns the daily energy use of the last days from
+// week_power, in Wh like the BRP069 week history; the adapter keeps no
+// hourly or monthly history. It reads the values of the last UpdateStatus
+// and sends no request.
+func (d *DaikinBRP084) GetEnergyHistory(now time.Time) (*EnergyHistory, error) {
+	value, exists := d.Values.Get("datas")
+	if !exists {
+		return nil, NewCapabilityError("energy consumption not reported by this unit", nil)
+	}
+
+	daily, err := parseEnergyList(value, 0.001)
+	if err != nil {
+		return nil, err
+	}
+	return &EnergyHistory{Daily: dailyReadings(daily, now)}, nil
+}
+
 // GetMAC returns device MAC address
 func (d *DaikinBRP084) GetMAC() string {
 	if mac, exists := d.Values.Get("mac"); exists {
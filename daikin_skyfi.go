@@ -11,6 +11,9 @@ import (
 type DaikinSkyFi struct {
 	*BaseAppliance
 	Password string
+
+	// Credentials, if set, supplies the password instead of Password
+	Credentials CredentialProvider
 }
 
 // NewDaikinSkyFi creates SkyFi device
@@ -43,6 +46,7 @@ func NewDaikinSkyFi(deviceIP, password string, logger Logger) *DaikinSkyFi {
 	base.HTTPResources = []string{"ac.cgi", "zones.cgi"}
 	base.InfoResources = base.HTTPResources
 	base.MaxConcurrentRequests = 1
+	base.deviceType = "SkyFi"
 
 	return &DaikinSkyFi{
 		BaseAppliance: base,
@@ -55,41 +59,55 @@ func (d *DaikinSkyFi) GetDeviceType() string {
 }
 
 func (d *DaikinSkyFi) Init(ctx context.Context) error {
+	password, err := d.password(ctx)
+	if err != nil {
+		return err
+	}
+
 	for _, resource := range d.HTTPResources {
-		params := map[string]string{"pass": d.Password}
-		data, err := d.getResource(ctx, resource, params)
+		params := map[string]string{"pass": password}
+		body, err := d.getRawResource(ctx, resource, params)
 		if err != nil {
 			d.Logger.Warn("Failed to get resource", "resource", resource, "error", err)
 			continue
 		}
 
-		skyfiData := d.parseSkyFiResponse(fmt.Sprintf("%v", data))
-		d.Values.UpdateByResource(resource, skyfiData)
+		d.Values.UpdateByResource(resource, d.parseSkyFiResponse(string(body)))
 	}
 	return nil
 }
 
-func (d *DaikinSkyFi) UpdateStatus(ctx context.Context) error {
+func (d *DaikinSkyFi) UpdateStatus(ctx context.Context) (err error) {
+	ctx, span := d.startSpan(ctx, "daikin.update_status")
+	defer func() { endSpan(span, err) }()
+
+	password, err := d.password(ctx)
+	if err != nil {
+		return err
+	}
+
 	for _, resource := range d.InfoResources {
 		if d.Values.ShouldResourceBeUpdated(resource) {
-			params := map[string]string{"pass": d.Password}
-			data, err := d.getResource(ctx, resource, params)
+			params := map[string]string{"pass": password}
+			body, err := d.getRawResource(ctx, resource, params)
 			if err != nil {
 				d.Logger.Warn("Failed to get resource", "resource", resource, "error", err)
 				continue
 			}
 
-			skyfiData := d.parseSkyFiResponse(fmt.Sprintf("%v", data))
-			d.Values.UpdateByResource(resource, skyfiData)
+			d.Values.UpdateByResource(resource, d.parseSkyFiResponse(string(body)))
 		}
 	}
 	return nil
 }
 
-func (d *DaikinSkyFi) Set(ctx context.Context, settings map[string]string) error {
+func (d *DaikinSkyFi) Set(ctx context.Context, settings map[string]string) (err error) {
+	ctx, span := d.startSpan(ctx, "daikin.set")
+	defer func() { endSpan(span, err) }()
+
 	d.Logger.Info("Updating SkyFi settings", "settings", settings)
 
-	err := d.UpdateStatus(ctx)
+	err = d.UpdateStatus(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to update status: %w", err)
 	}
@@ -109,7 +127,7 @@ func (d *DaikinSkyFi) Set(ctx context.Context, settings map[string]string) error
 		params := map[string]string{
 			"p": d.Values.All()["opmode"],
 		}
-		_, err := d.getResource(ctx, "set.cgi", params)
+		_, err := d.getRawResource(ctx, "set.cgi", params)
 		if err != nil {
 			return fmt.Errorf("failed to turn off: %w", err)
 		}
@@ -127,7 +145,7 @@ func (d *DaikinSkyFi) Set(ctx context.Context, settings map[string]string) error
 			"m": allValues["acmode"],
 		}
 
-		_, err := d.getResource(ctx, "set.cgi", params)
+		_, err := d.getRawResource(ctx, "set.cgi", params)
 		if err != nil {
 			return fmt.Errorf("failed to set control: %w", err)
 		}
@@ -136,19 +154,23 @@ func (d *DaikinSkyFi) Set(ctx context.Context, settings map[string]string) error
 	return nil
 }
 
+// password returns the current password, from Credentials if set
+func (d *DaikinSkyFi) password(ctx context.Context) (string, error) {
+	if d.Credentials == nil {
+		return d.Password, nil
+	}
+
+	credentials, err := d.Credentials.Credentials(ctx)
+	if err != nil {
+		return "", NewAuthenticationError("failed to get credentials", err)
+	}
+	return credentials.Password, nil
+}
+
 func (d *DaikinSkyFi) parseSkyFiResponse(response string) map[string]string {
 	d.Logger.Debug("Parsing SkyFi response", "response", response)
 
-	result := make(map[string]string)
-	pairs := strings.Split(response, "&")
-
-	for _, pair := range pairs {
-		if parts := strings.SplitN(pair, "=", 2); len(parts) == 2 {
-			key := parts[0]
-			value := parts[1]
-			result[key] = value
-		}
-	}
+	result := decodeKeyValues(response, "&")
 
 	if fanflags, exists := result["fanflags"]; exists && fanflags == "3" {
 		if fanspeed, exists := result["fanspeed"]; exists {
@@ -279,13 +301,12 @@ func (d *DaikinSkyFi) SetZone(ctx context.Context, zoneID int, key string, value
 		"s": fmt.Sprintf("%v", value),
 	}
 
-	response, err := d.getResource(ctx, "setzone.cgi", params)
+	body, err := d.getRawResource(ctx, "setzone.cgi", params)
 	if err != nil {
 		return fmt.Errorf("failed to set zone: %w", err)
 	}
 
-	skyfiData := d.parseSkyFiResponse(fmt.Sprintf("%v", response))
-	d.Values.Update(skyfiData)
+	d.Values.Update(d.parseSkyFiResponse(string(body)))
 
 	return nil
 }
@@ -0,0 +1,316 @@
+package godaikin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// diagnosticExtraResources are key=value resources fetched for diagnostics
+// on top of the BRP069 HTTPResources
+var diagnosticExtraResources = []string{
+	"common/get_wifi_setting",
+	"aircon/get_scdltimer_info",
+	"aircon/get_timer",
+	"aircon/get_demand_control",
+}
+
+// brp084DiagnosticRequests are the multireq targets read for diagnostics
+var brp084DiagnosticRequests = []string{
+	"/dsiot/edge/adr_0100.dgc_status?filter=pv,pt,md",
+	"/dsiot/edge/adr_0200.dgc_status?filter=pv,pt,md",
+	"/dsiot/edge/adr_0100.i_power.week_power?filter=pv,pt,md",
+	"/dsiot/edge.adp_i",
+	"/dsiot/edge.adp_d",
+}
+
+// Diagnostics is a support bundle describing how a device responded to
+// detection and to every known resource
+type Diagnostics struct {
+	Address    string             `json:"address"`
+	StartedAt  time.Time          `json:"started_at"`
+	DurationMS float64            `json:"duration_ms"`
+	Redacted   bool               `json:"redacted"`
+	DeviceType string             `json:"device_type,omitempty"`
+	Error      string             `json:"error,omitempty"`
+	Probes     []ProbeDiagnostic  `json:"probes"`
+	Resources  []ResourceExchange `json:"resources"`
+}
+
+// ProbeDiagnostic is the outcome of a detection probe
+type ProbeDiagnostic struct {
+	Name      string  `json:"name"`
+	Success   bool    `json:"success"`
+	Error     string  `json:"error,omitempty"`
+	LatencyMS float64 `json:"latency_ms"`
+}
+
+// ResourceExchange is a raw request made for diagnostics and its response
+type ResourceExchange struct {
+	Family      string  `json:"family"`
+	Method      string  `json:"method"`
+	URL         string  `json:"url"`
+	RequestBody string  `json:"request_body,omitempty"`
+	Status      int     `json:"status,omitempty"`
+	LatencyMS   float64 `json:"latency_ms"`
+	Body        string  `json:"body,omitempty"`
+	Error       string  `json:"error,omitempty"`
+}
+
+// DiagnoseOption configures Diagnose
+type DiagnoseOption func(*diagnoseConfig)
+
+type diagnoseConfig struct {
+	options []Option
+	redact  bool
+	output  io.Writer
+	logger  Logger
+}
+
+// WithDeviceOptions passes device options such as WithPassword or WithKey
+func WithDeviceOptions(options ...Option) DiagnoseOption {
+	return func(c *diagnoseConfig) {
+		c.options = append(c.options, options...)
+	}
+}
+
+// WithRedaction removes passwords, keys, UUIDs and MAC addresses from the report
+func WithRedaction() DiagnoseOption {
+	return func(c *diagnoseConfig) {
+		c.redact = true
+	}
+}
+
+// WithDiagnosticsOutput writes the report as JSON to w
+func WithDiagnosticsOutput(w io.Writer) DiagnoseOption {
+	return func(c *diagnoseConfig) {
+		c.output = w
+	}
+}
+
+// WithDiagnosticsLogger logs the detection steps to logger
+func WithDiagnosticsLogger(logger Logger) DiagnoseOption {
+	return func(c *diagnoseConfig) {
+		c.logger = logger
+	}
+}
+
+// Diagnose runs device detection against deviceID, then fetches every
+// known resource of every driver family and records the raw exchanges.
+// It does not change the device: clock sync and terminal registration are
+// skipped.
+// Detection and request failures are recorded in the report; the returned
+// error only reports a failure to write the output.
+func Diagnose(ctx context.Context, deviceID string, opts ...DiagnoseOption) (*Diagnostics, error) {
+	diagConfig := &diagnoseConfig{logger: NoOpLogger{}}
+	for _, opt := range opts {
+		opt(diagConfig)
+	}
+
+	config := &Config{}
+	for _, opt := range diagConfig.options {
+		opt(config)
+	}
+	// Diagnostics only read: the clock is left alone and a BRP072C terminal
+	// is not registered, so pass the UUID already registered with it
+	config.DisableClockSync = true
+	config.skipRegistration = true
+
+	report := &Diagnostics{
+		Address:   deviceID,
+		StartedAt: time.Now(),
+		Redacted:  diagConfig.redact,
+		Probes:    []ProbeDiagnostic{},
+		Resources: []ResourceExchange{},
+	}
+
+	observe := func(probe string, elapsed time.Duration, err error) {
+		result := ProbeDiagnostic{
+			Name:      probe,
+			Success:   err == nil,
+			LatencyMS: milliseconds(elapsed),
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		report.Probes = append(report.Probes, result)
+	}
+
+	device, err := createDaikinDevice(ctx, deviceID, diagConfig.logger, config, observe)
+	if err != nil {
+		report.Error = err.Error()
+	} else {
+		report.DeviceType = device.GetDeviceType()
+	}
+
+	// Detection already reported any failure to get credentials
+	credentials, _ := config.credentialProvider().Credentials(ctx)
+
+	deviceIP, devicePort := extractIPPort(deviceID)
+	for _, family := range diagnosticFamilies(deviceIP, devicePort, diagConfig.logger, config, credentials) {
+		for _, request := range family.requests {
+			report.Resources = append(report.Resources, fetchDiagnostic(ctx, family.name, family.base, request))
+		}
+	}
+
+	report.DurationMS = milliseconds(time.Since(report.StartedAt))
+
+	if diagConfig.redact {
+		redactDiagnostics(report)
+	}
+
+	if diagConfig.output != nil {
+		if err := report.WriteJSON(diagConfig.output); err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+// WriteJSON writes the report as a single JSON document
+func (d *Diagnostics) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(d); err != nil {
+		return fmt.Errorf("failed to write diagnostics: %w", err)
+	}
+	return nil
+}
+
+type diagnosticRequest struct {
+	method string
+	path   string
+	params map[string]string
+	body   []byte
+}
+
+type diagnosticFamily struct {
+	name     string
+	base     *BaseAppliance
+	requests []diagnosticRequest
+}
+
+// diagnosticFamilies builds one appliance per driver family, addressed the
+// same way the detection probes address it
+func diagnosticFamilies(deviceIP string, devicePort int, logger Logger, config *Config, credentials Credentials) []diagnosticFamily {
+	var families []diagnosticFamily
+
+	brp084 := NewDaikinBRP084(deviceIP, logger)
+	payload, _ := json.Marshal(newReadRequest(brp084DiagnosticRequests...))
+	families = append(families, diagnosticFamily{
+		name:     "BRP084",
+		base:     brp084.BaseAppliance,
+		requests: []diagnosticRequest{{method: "POST", path: "dsiot/multireq", body: payload}},
+	})
+
+	brp069 := NewDaikinBRP069(deviceIP, logger)
+	family := diagnosticFamily{name: "BRP069", base: brp069.BaseAppliance}
+	if credentials.Key != "" {
+		brp072c := NewDaikinBRP072C(deviceIP, credentials.Key, credentials.UUID, logger)
+		family = diagnosticFamily{name: "BRP072C", base: brp072c.BaseAppliance}
+	}
+	for _, resource := range append(append([]string{}, brp069.HTTPResources...), diagnosticExtraResources...) {
+		family.requests = append(family.requests, diagnosticRequest{method: "GET", path: resource})
+	}
+	families = append(families, family)
+
+	airbase := NewDaikinAirBase(deviceIP, logger)
+	family = diagnosticFamily{name: "AirBase", base: airbase.BaseAppliance}
+	for _, resource := range airbase.HTTPResources {
+		family.requests = append(family.requests, diagnosticRequest{method: "GET", path: "skyfi/" + resource})
+	}
+	families = append(families, family)
+
+	if credentials.Password != "" {
+		skyfi := NewDaikinSkyFi(deviceIP, credentials.Password, logger)
+		family = diagnosticFamily{name: "SkyFi", base: skyfi.BaseAppliance}
+		for _, resource := range skyfi.HTTPResources {
+			family.requests = append(family.requests, diagnosticRequest{
+				method: "GET",
+				path:   resource,
+				params: map[string]string{"pass": credentials.Password},
+			})
+		}
+		families = append(families, family)
+	}
+
+	for _, family := range families {
+		applyConfig(family.base, config)
+		if devicePort == 0 {
+			continue
+		}
+		switch family.name {
+		case "BRP072C":
+			family.base.BaseURL = fmt.Sprintf("https://%s:%d", deviceIP, devicePort)
+		default:
+			family.base.BaseURL = fmt.Sprintf("http://%s:%d", deviceIP, devicePort)
+		}
+	}
+
+	return families
+}
+
+// fetchDiagnostic performs a raw request and records everything about it
+func fetchDiagnostic(ctx context.Context, family string, base *BaseAppliance, request diagnosticRequest) ResourceExchange {
+	exchange := ResourceExchange{
+		Family:      family,
+		Method:      request.method,
+		RequestBody: string(request.body),
+	}
+
+	req, err := base.newRequest(ctx, request.method, request.path, request.params, request.body)
+	if err != nil {
+		exchange.Error = err.Error()
+		return exchange
+	}
+	exchange.URL = req.URL.String()
+
+	start := time.Now()
+	resp, err := base.HTTPClient.Do(req)
+	if err != nil {
+		exchange.LatencyMS = milliseconds(time.Since(start))
+		exchange.Error = err.Error()
+		return exchange
+	}
+	defer resp.Body.Close()
+
+	body, err := readBody(resp.Body, base.MaxResponseSize)
+	exchange.LatencyMS = milliseconds(time.Since(start))
+	exchange.Status = resp.StatusCode
+	exchange.Body = string(body)
+	if err != nil {
+		exchange.Error = err.Error()
+	} else if resp.StatusCode != http.StatusOK {
+		exchange.Error = http.StatusText(resp.StatusCode)
+	}
+
+	return exchange
+}
+
+// redactDiagnostics removes credentials and identifiers from a report
+func redactDiagnostics(report *Diagnostics) {
+	report.Error = redactText(report.Error)
+	for i := range report.Probes {
+		report.Probes[i].Error = redactText(report.Probes[i].Error)
+	}
+	for i := range report.Resources {
+		exchange := &report.Resources[i]
+		exchange.URL = redactText(exchange.URL)
+		exchange.RequestBody = redactText(exchange.RequestBody)
+		exchange.Body = redactText(exchange.Body)
+		exchange.Error = redactText(exchange.Error)
+	}
+}
+
+// redactText removes credentials and device identifiers from text
+func redactText(text string) string {
+	return redactIdentifiers(redactSecrets(text))
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,264 @@
+package godaikin
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// EnergyReading is the energy used from Start for one hour, day or month
+type EnergyReading struct {
+	Start time.Time
+	// Energy is in kWh
+	Energy float64
+}
+
+// EnergyHistory is the energy use an appliance reports, oldest first.
+// Units keep only part of it: hours of today and yesterday, the last days
+// and the months of this and last year.
+type EnergyHistory struct {
+	Hourly  []EnergyReading
+	Daily   []EnergyReading
+	Monthly []EnergyReading
+}
+
+// EnergyReporter is implemented by appliances reporting their energy
+// history. The history comes from the values of the last UpdateStatus, so
+// call it first for current figures; no request is sent. Readings are
+// placed relative to now, in its location, which should be the time zone
+// of the unit's clock.
+type EnergyReporter interface {
+	GetEnergyHistory(now time.Time) (*EnergyHistory, error)
+}
+
+// PriceReporter is implemented by appliances storing an electricity price
+type PriceReporter interface {
+	// GetPrice returns the price per kWh configured on the unit, from the
+	// values of the last UpdateStatus
+	GetPrice() (float64, error)
+}
+
+// Tariff is an electricity price per kWh that may vary with time
+type Tariff interface {
+	Rate(at time.Time) float64
+}
+
+// FlatRate is a tariff with the same price at all times
+type FlatRate float64
+
+func (r FlatRate) Rate(time.Time) float64 {
+	return float64(r)
+}
+
+// TariffPeriod prices the hours from Start to End, which may wrap past
+// midnight, on Days or every day when empty
+type TariffPeriod struct {
+	Days  []time.Weekday
+	Start int
+	End   int
+	Rate  float64
+}
+
+func (p TariffPeriod) covers(at time.Time) bool {
+	if len(p.Days) > 0 {
+		day := at.Weekday()
+		if p.Start > p.End && at.Hour() < p.End {
+			// The period started the day before
+			day = (day + 6) % 7
+		}
+		found := false
+		for _, d := range p.Days {
+			found = found || d == day
+		}
+		if !found {
+			return false
+		}
+	}
+
+	hour := at.Hour()
+	if p.Start <= p.End {
+		return hour >= p.Start && hour < p.End
+	}
+	return hour >= p.Start || hour < p.End
+}
+
+// TimeOfUseTariff prices each hour at the first period covering it, or at
+// Default
+type TimeOfUseTariff struct {
+	Default float64
+	Periods []TariffPeriod
+}
+
+func (t TimeOfUseTariff) Rate(at time.Time) float64 {
+	for _, period := range t.Periods {
+		if period.covers(at) {
+			return period.Rate
+		}
+	}
+	return t.Default
+}
+
+// averageRate is the mean hourly rate from start to end
+func averageRate(tariff Tariff, start, end time.Time) float64 {
+	total, hours := 0.0, 0
+	for at := start; at.Before(end); at = at.Add(time.Hour) {
+		total += tariff.Rate(at)
+		hours++
+	}
+	if hours == 0 {
+		return tariff.Rate(start)
+	}
+	return total / float64(hours)
+}
+
+// PeriodCost is the energy used and its cost over a day or month
+type PeriodCost struct {
+	Start  time.Time
+	Energy float64
+	Cost   float64
+}
+
+// CostReport breaks down an appliance's energy cost by day and month
+type CostReport struct {
+	Appliance string
+	Daily     []PeriodCost
+	Monthly   []PeriodCost
+}
+
+// DailyTotal returns the cost of the days in Daily. Units keep only the
+// last days, so this is usually less than the sum of Monthly.
+func (r *CostReport) DailyTotal() float64 {
+	total := 0.0
+	for _, day := range r.Daily {
+		total += day.Cost
+	}
+	return total
+}
+
+// CalculateCost prices an energy history. Days with hourly readings are
+// priced hour by hour; other days and months at the tariff's average rate
+// over them. Without monthly readings the months are summed from the days.
+func CalculateCost(history *EnergyHistory, tariff Tariff) *CostReport {
+	report := &CostReport{}
+
+	hourly := make(map[time.Time][]EnergyReading)
+	for _, hour := range history.Hourly {
+		day := startOfDay(hour.Start)
+		hourly[day] = append(hourly[day], hour)
+	}
+
+	days := make(map[time.Time]PeriodCost)
+	for _, day := range history.Daily {
+		days[startOfDay(day.Start)] = PeriodCost{
+			Start:  day.Start,
+			Energy: day.Energy,
+			Cost:   day.Energy * averageRate(tariff, day.Start, day.Start.AddDate(0, 0, 1)),
+		}
+	}
+	for day, hours := range hourly {
+		cost := PeriodCost{Start: day}
+		for _, hour := range hours {
+			cost.Energy += hour.Energy
+			cost.Cost += hour.Energy * tariff.Rate(hour.Start)
+		}
+		days[day] = cost
+	}
+	for _, cost := range days {
+		report.Daily = append(report.Daily, cost)
+	}
+	sortCosts(report.Daily)
+
+	for _, month := range history.Monthly {
+		report.Monthly = append(report.Monthly, PeriodCost{
+			Start:  month.Start,
+			Energy: month.Energy,
+			Cost:   month.Energy * averageRate(tariff, month.Start, month.Start.AddDate(0, 1, 0)),
+		})
+	}
+	if len(history.Monthly) == 0 {
+		months := make(map[time.Time]PeriodCost)
+		for _, day := range report.Daily {
+			start := time.Date(day.Start.Year(), day.Start.Month(), 1, 0, 0, 0, 0, day.Start.Location())
+			month := months[start]
+			month.Start = start
+			month.Energy += day.Energy
+			month.Cost += day.Cost
+			months[start] = month
+		}
+		for _, cost := range months {
+			report.Monthly = append(report.Monthly, cost)
+		}
+	}
+	sortCosts(report.Monthly)
+
+	return report
+}
+
+// CostReports prices the energy history of each appliance that reports
+// one, keyed by the given names. A nil tariff uses each unit's configured
+// price. Histories and prices are those of the appliances' last
+// UpdateStatus.
+func CostReports(appliances map[string]Appliance, tariff Tariff, now time.Time) (map[string]*CostReport, error) {
+	reports := make(map[string]*CostReport)
+	for name, appliance := range appliances {
+		reporter, ok := appliance.(EnergyReporter)
+		if !ok || !appliance.SupportsEnergyConsumption() {
+			continue
+		}
+
+		history, err := reporter.GetEnergyHistory(now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get energy history of %s: %w", name, err)
+		}
+
+		rate := tariff
+		if rate == nil {
+			pricer, ok := appliance.(PriceReporter)
+			if !ok {
+				return nil, NewCapabilityError(fmt.Sprintf("%s has no configured price", name), nil)
+			}
+			price, err := pricer.GetPrice()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get price of %s: %w", name, err)
+			}
+			rate = FlatRate(price)
+		}
+
+		report := CalculateCost(history, rate)
+		report.Appliance = name
+		reports[name] = report
+	}
+	return reports, nil
+}
+
+func sortCosts(costs []PeriodCost) {
+	sort.Slice(costs, func(i, j int) bool { return costs[i].Start.Before(costs[j].Start) })
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// parseEnergyList decodes a "/" separated history in units of scale kWh
+func parseEnergyList(value string, scale float64) ([]float64, error) {
+	var energies []float64
+	for _, item := range decodeList(value, "/") {
+		energy, err := strconv.ParseFloat(item, 64)
+		if err != nil {
+			return nil, NewParseError(fmt.Sprintf("invalid energy value %q", item), err)
+		}
+		energies = append(energies, energy*scale)
+	}
+	return energies, nil
+}
+
+// dailyReadings places a history of days ending today
+func dailyReadings(energies []float64, now time.Time) []EnergyReading {
+	readings := make([]EnergyReading, len(energies))
+	today := startOfDay(now)
+	for i, energy := range energies {
+		readings[i] = EnergyReading{Start: today.AddDate(0, 0, i-len(energies)+1), Energy: energy}
+	}
+	return readings
+}
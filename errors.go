@@ -7,11 +7,12 @@ type DaikinError struct {
 	Err     error
 }
 
+// Error returns the message with any credentials redacted
 func (e *DaikinError) Error() string {
 	if e.Err != nil {
-		return fmt.Sprintf("daikin error: %s: %v", e.Message, e.Err)
+		return redactSecrets(fmt.Sprintf("daikin error: %s: %v", e.Message, e.Err))
 	}
-	return fmt.Sprintf("daikin error: %s", e.Message)
+	return redactSecrets(fmt.Sprintf("daikin error: %s", e.Message))
 }
 
 func (e *DaikinError) Unwrap() error {
@@ -54,3 +55,14 @@ func NewParseError(message string, err error) *ParseError {
 		DaikinError: NewDaikinError(message, err),
 	}
 }
+
+// CapabilityError reports a feature the device does not support
+type CapabilityError struct {
+	*DaikinError
+}
+
+func NewCapabilityError(message string, err error) *CapabilityError {
+	return &CapabilityError{
+		DaikinError: NewDaikinError(message, err),
+	}
+}
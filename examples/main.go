@@ -42,7 +42,7 @@ func main() {
 	fmt.Printf("✅ Connected to %s device\n", device.GetDeviceType())
 	fmt.Printf("📍 IP: %s\n", device.GetDeviceIP())
 	fmt.Printf("🔧 MAC: %s\n", device.GetMAC())
-	fmt.Printf("⚡ Power: %t\n", device.GetPowerState())
+	fmt.Printf("⚡ Power: %s\n", device.GetPowerState())
 	fmt.Printf("🎛️  Mode: %s\n", device.GetMode())
 
 	if temp, err := device.GetTargetTemperature(); err == nil {
@@ -3,8 +3,13 @@ package godaikin
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"regexp"
 	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // CreateDaikinDevice creates the appropriate Daikin device based on auto-detection
@@ -16,100 +21,184 @@ func CreateDaikinDevice(deviceID string, logger Logger, options ...Option) (Appl
 		opt(config)
 	}
 
+	return createDaikinDevice(context.Background(), deviceID, logger, config, nil)
+}
+
+// probeObserver is notified of the outcome of every detection probe
+type probeObserver func(probe string, elapsed time.Duration, err error)
+
+// detectionProbe is a single step of device auto-detection
+type detectionProbe struct {
+	name   string
+	create func(ctx context.Context) (Appliance, error)
+}
+
+// createDaikinDevice runs the detection probes in order and returns the first
+// device that initializes successfully
+func createDaikinDevice(ctx context.Context, deviceID string, logger Logger, config *Config, observe probeObserver) (Appliance, error) {
 	// Extract IP and port from deviceID
 	deviceIP, devicePort := extractIPPort(deviceID)
 
-	ctx := context.Background()
+	logger = NewRedactingLogger(logger)
+	tracer := newTracer(config.TracerProvider)
 
-	// If password is provided, it's a SkyFi device
-	if config.Password != "" {
-		logger.Info("Detected SkyFi device", "ip", deviceIP, "password_provided", true)
-		device := NewDaikinSkyFi(deviceIP, config.Password, logger)
-		if devicePort != 0 && devicePort != 2000 {
-			device.BaseURL = fmt.Sprintf("http://%s:%d", deviceIP, devicePort)
-			logger.Debug("Using custom port for SkyFi", "port", devicePort)
-		}
-		err := device.Init(ctx)
-		if err != nil {
-			logger.Error("Failed to initialize SkyFi device", "error", err)
-			return nil, fmt.Errorf("failed to initialize SkyFi device: %w", err)
-		}
-		logger.Info("Successfully initialized SkyFi device", "ip", deviceIP)
-		return device, nil
+	credentials, err := config.credentialProvider().Credentials(ctx)
+	if err != nil {
+		return nil, NewAuthenticationError("failed to get credentials", err)
 	}
 
-	// If key is provided, it's a BRP072C device
-	if config.Key != "" {
-		logger.Info("Detected BRP072C device", "ip", deviceIP, "key_provided", true)
-		device := NewDaikinBRP072C(deviceIP, config.Key, config.UUID, logger)
-		if devicePort != 0 && devicePort != 443 {
-			device.BaseURL = fmt.Sprintf("https://%s:%d", deviceIP, devicePort)
-			logger.Debug("Using custom port for BRP072C", "port", devicePort)
+	var lastErr error
+	for _, probe := range detectionProbes(deviceIP, devicePort, logger, config, credentials) {
+		probeCtx, span := tracer.Start(ctx, "daikin.detect",
+			trace.WithAttributes(attrDeviceIP.String(deviceIP), attrProbe.String(probe.name)))
+
+		start := time.Now()
+		device, err := probe.create(probeCtx)
+		if observe != nil {
+			observe(probe.name, time.Since(start), err)
 		}
-		err := device.Init(ctx)
-		if err != nil {
-			logger.Error("Failed to initialize BRP072C device", "error", err)
-			return nil, fmt.Errorf("failed to initialize BRP072C device: %w", err)
+		if err == nil {
+			span.SetAttributes(attrDeviceType.String(device.GetDeviceType()))
 		}
-		logger.Info("Successfully initialized BRP072C device", "ip", deviceIP)
-		return device, nil
+		endSpan(span, err)
+
+		if err == nil {
+			registerMetrics(device, config, logger)
+			return device, nil
+		}
+		lastErr = err
 	}
 
-	// Special case for BRP069, AirBase, and BRP firmware 2.8.0
+	return nil, lastErr
+}
 
-	// First try to check if it's firmware 2.8.0
-	logger.Debug("Trying connection to firmware 2.8.0", "ip", deviceIP)
-	if device, err := tryBRP084Device(deviceIP, devicePort, logger); err == nil {
-		logger.Info("Successfully connected to firmware 2.8.0 device", "ip", deviceIP)
-		// Initialize mode to "off" if we couldn't read it
-		if mode := device.GetMode(); mode == "" || mode == "unknown" {
-			logger.Debug("Initializing mode to off for device with unknown mode")
-			if baseDevice, ok := device.(*BaseAppliance); ok {
-				baseDevice.Values.Set("mode", "off")
-				baseDevice.Values.Set("pow", "0")
-			}
+// registerMetrics exports the health of a detected device, if a meter
+// provider is configured
+func registerMetrics(device Appliance, config *Config, logger Logger) {
+	if config.MeterProvider == nil {
+		return
+	}
+	if registrar, ok := device.(interface {
+		registerHealthMetrics(metric.MeterProvider) error
+	}); ok {
+		if err := registrar.registerHealthMetrics(config.MeterProvider); err != nil {
+			logger.Warn("Failed to register health metrics", "error", err)
 		}
-		return device, nil
-	} else {
-		logger.Debug("Not a firmware 2.8.0 device", "error", err)
 	}
+}
 
-	// Try BRP069
-	logger.Debug("Trying connection to BRP069", "ip", deviceIP)
-	if device, err := tryBRP069Device(deviceIP, devicePort, logger); err == nil {
-		logger.Info("Successfully connected to BRP069 device", "ip", deviceIP)
-		return device, nil
-	} else {
-		logger.Debug("Falling back to AirBase", "error", err)
+// detectionProbes returns the probes to try for a device, in order
+func detectionProbes(deviceIP string, devicePort int, logger Logger, config *Config, credentials Credentials) []detectionProbe {
+	// If password is provided, it's a SkyFi device
+	if credentials.Password != "" {
+		return []detectionProbe{{
+			name: "SkyFi",
+			create: func(ctx context.Context) (Appliance, error) {
+				return trySkyFiDevice(ctx, deviceIP, devicePort, logger, config, credentials)
+			},
+		}}
 	}
 
-	// Fallback to AirBase
-	logger.Debug("Trying AirBase connection", "ip", deviceIP)
-	device := NewDaikinAirBase(deviceIP, logger)
-	if devicePort != 0 && devicePort != 80 {
-		logger.Debug("Using custom port for AirBase", "port", devicePort)
-		device.BaseURL = fmt.Sprintf("http://%s:%d", deviceIP, devicePort)
+	// If key is provided, it's a BRP072C device
+	if credentials.Key != "" {
+		return []detectionProbe{{
+			name: "BRP072C",
+			create: func(ctx context.Context) (Appliance, error) {
+				return tryBRP072CDevice(ctx, deviceIP, devicePort, logger, config, credentials)
+			},
+		}}
+	}
+
+	// Special case for BRP069, AirBase, and BRP firmware 2.8.0
+	return []detectionProbe{
+		{
+			name: "BRP084",
+			create: func(ctx context.Context) (Appliance, error) {
+				// First try to check if it's firmware 2.8.0
+				logger.Debug("Trying connection to firmware 2.8.0", "ip", deviceIP)
+				device, err := tryBRP084Device(ctx, deviceIP, devicePort, logger, config)
+				if err != nil {
+					logger.Debug("Not a firmware 2.8.0 device", "error", err)
+					return nil, err
+				}
+				logger.Info("Successfully connected to firmware 2.8.0 device", "ip", deviceIP)
+				return device, nil
+			},
+		},
+		{
+			name: "BRP069",
+			create: func(ctx context.Context) (Appliance, error) {
+				logger.Debug("Trying connection to BRP069", "ip", deviceIP)
+				device, err := tryBRP069Device(ctx, deviceIP, devicePort, logger, config)
+				if err != nil {
+					logger.Debug("Falling back to AirBase", "error", err)
+					return nil, err
+				}
+				logger.Info("Successfully connected to BRP069 device", "ip", deviceIP)
+				return device, nil
+			},
+		},
+		{
+			name: "AirBase",
+			create: func(ctx context.Context) (Appliance, error) {
+				// Fallback to AirBase
+				logger.Debug("Trying AirBase connection", "ip", deviceIP)
+				return tryAirBaseDevice(ctx, deviceIP, devicePort, logger, config)
+			},
+		},
 	}
+}
 
+// trySkyFiDevice attempts to create SkyFi device
+func trySkyFiDevice(ctx context.Context, deviceIP string, devicePort int, logger Logger, config *Config, credentials Credentials) (Appliance, error) {
+	logger.Info("Detected SkyFi device", "ip", deviceIP, "password_provided", true)
+	device := NewDaikinSkyFi(deviceIP, credentials.Password, logger)
+	applyConfig(device.BaseAppliance, config)
+	if config.Credentials != nil {
+		device.Credentials = config.credentialProvider()
+	}
+	if devicePort != 0 && devicePort != 2000 {
+		device.BaseURL = fmt.Sprintf("http://%s:%d", deviceIP, devicePort)
+		logger.Debug("Using custom port for SkyFi", "port", devicePort)
+	}
 	err := device.Init(ctx)
 	if err != nil {
-		logger.Error("Failed to initialize AirBase device", "error", err)
-		return nil, fmt.Errorf("failed to initialize AirBase device: %w", err)
+		logger.Error("Failed to initialize SkyFi device", "error", err)
+		return nil, fmt.Errorf("failed to initialize SkyFi device: %w", err)
 	}
+	logger.Info("Successfully initialized SkyFi device", "ip", deviceIP)
+	return device, nil
+}
 
-	// Check if device was successfully initialized
-	if mode := device.GetMode(); mode == "" {
-		logger.Error("Device not supported or failed to initialize", "device_id", deviceID)
-		return nil, fmt.Errorf("error creating device, %s is not supported", deviceID)
+// tryBRP072CDevice attempts to create BRP072C device
+func tryBRP072CDevice(ctx context.Context, deviceIP string, devicePort int, logger Logger, config *Config, credentials Credentials) (Appliance, error) {
+	logger.Info("Detected BRP072C device", "ip", deviceIP, "key_provided", true)
+	device := NewDaikinBRP072C(deviceIP, credentials.Key, credentials.UUID, logger)
+	applyConfig(device.BaseAppliance, config)
+	if config.Credentials != nil {
+		device.Credentials = config.credentialProvider()
 	}
-
-	logger.Info("Successfully created Daikin device", "type", fmt.Sprintf("%T", device), "ip", deviceIP)
+	if devicePort != 0 && devicePort != 443 {
+		device.BaseURL = fmt.Sprintf("https://%s:%d", deviceIP, devicePort)
+		logger.Debug("Using custom port for BRP072C", "port", devicePort)
+	}
+	init := device.Init
+	if config.skipRegistration {
+		init = device.DaikinBRP069.Init
+	}
+	err := init(ctx)
+	if err != nil {
+		logger.Error("Failed to initialize BRP072C device", "error", err)
+		return nil, fmt.Errorf("failed to initialize BRP072C device: %w", err)
+	}
+	logger.Info("Successfully initialized BRP072C device", "ip", deviceIP)
 	return device, nil
 }
 
 // tryBRP084Device attempts to create firmware 2.8.0 device
-func tryBRP084Device(deviceIP string, devicePort int, logger Logger) (Appliance, error) {
+func tryBRP084Device(ctx context.Context, deviceIP string, devicePort int, logger Logger, config *Config) (Appliance, error) {
 	device := NewDaikinBRP084(deviceIP, logger)
+	applyConfig(device.BaseAppliance, config)
 
 	// If we have a specific port from discovery, set it in the base_url
 	if devicePort != 0 && devicePort != 80 {
@@ -118,8 +207,6 @@ func tryBRP084Device(deviceIP string, devicePort int, logger Logger) (Appliance,
 		device.URL = fmt.Sprintf("%s/dsiot/multireq", device.BaseURL)
 	}
 
-	ctx := context.Background()
-
 	// Try to initialize the device by updating status
 	err := device.UpdateStatus(ctx)
 	if err != nil {
@@ -130,12 +217,20 @@ func tryBRP084Device(deviceIP string, devicePort int, logger Logger) (Appliance,
 		return nil, fmt.Errorf("empty values from BRP084 device")
 	}
 
+	// Initialize mode to "off" if we couldn't read it
+	if mode := device.GetMode(); mode == "" || mode == "unknown" {
+		logger.Debug("Initializing mode to off for device with unknown mode")
+		device.Values.Set("mode", "off")
+		device.Values.Set("pow", "0")
+	}
+
 	return device, nil
 }
 
 // tryBRP069Device attempts to create BRP069 device
-func tryBRP069Device(deviceIP string, devicePort int, logger Logger) (Appliance, error) {
+func tryBRP069Device(ctx context.Context, deviceIP string, devicePort int, logger Logger, config *Config) (Appliance, error) {
 	device := NewDaikinBRP069(deviceIP, logger)
+	applyConfig(device.BaseAppliance, config)
 
 	// If we have a specific port from discovery, set it in the base_url
 	if devicePort != 0 && devicePort != 80 {
@@ -143,8 +238,6 @@ func tryBRP069Device(deviceIP string, devicePort int, logger Logger) (Appliance,
 		device.BaseURL = fmt.Sprintf("http://%s:%d", deviceIP, devicePort)
 	}
 
-	ctx := context.Background()
-
 	// Try to update status with first HTTP resource
 	err := device.updateStatusWithResources(ctx, []string{"common/basic_info"})
 	if err != nil {
@@ -164,6 +257,50 @@ func tryBRP069Device(deviceIP string, devicePort int, logger Logger) (Appliance,
 	return device, nil
 }
 
+// tryAirBaseDevice attempts to create AirBase device
+func tryAirBaseDevice(ctx context.Context, deviceIP string, devicePort int, logger Logger, config *Config) (Appliance, error) {
+	device := NewDaikinAirBase(deviceIP, logger)
+	applyConfig(device.BaseAppliance, config)
+	if devicePort != 0 && devicePort != 80 {
+		logger.Debug("Using custom port for AirBase", "port", devicePort)
+		device.BaseURL = fmt.Sprintf("http://%s:%d", deviceIP, devicePort)
+	}
+
+	err := device.Init(ctx)
+	if err != nil {
+		logger.Error("Failed to initialize AirBase device", "error", err)
+		return nil, fmt.Errorf("failed to initialize AirBase device: %w", err)
+	}
+
+	// Check if device was successfully initialized
+	if mode := device.GetMode(); mode == "" {
+		logger.Error("Device not supported or failed to initialize", "ip", deviceIP)
+		return nil, fmt.Errorf("error creating device, %s is not supported", deviceIP)
+	}
+
+	logger.Info("Successfully created Daikin device", "type", fmt.Sprintf("%T", device), "ip", deviceIP)
+	return device, nil
+}
+
+// applyConfig applies the transport, tracing and limit options to a device
+func applyConfig(base *BaseAppliance, config *Config) {
+	if wrapper, ok := config.Transport.(interface {
+		wrap(next http.RoundTripper) http.RoundTripper
+	}); ok {
+		base.HTTPClient.Transport = wrapper.wrap(base.HTTPClient.Transport)
+	} else if config.Transport != nil {
+		base.HTTPClient.Transport = config.Transport
+	}
+	if config.MaxResponseSize > 0 {
+		base.MaxResponseSize = config.MaxResponseSize
+	}
+	if config.TracerProvider != nil {
+		base.Tracer = newTracer(config.TracerProvider)
+	}
+	base.ClockLocation = config.ClockLocation
+	base.DisableClockSync = config.DisableClockSync
+}
+
 // extractIPPort extracts IP address and port
 func extractIPPort(deviceID string) (string, int) {
 	// Check if there's a port specified in the device_id
@@ -0,0 +1,245 @@
+package godaikin
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultFaultPollInterval is how often WatchFaults polls a unit
+const DefaultFaultPollInterval = time.Minute
+
+// FaultDescriptions are the Daikin fault codes shown on the unit's remote
+// controller and in service manuals
+var FaultDescriptions = map[string]string{
+	"A0": "External protection device activated",
+	"A1": "Indoor unit PCB defect",
+	"A3": "Drain level control system abnormality",
+	"A5": "Freeze-up protection or high pressure control in heating",
+	"A6": "Indoor fan motor locked or overloaded",
+	"A7": "Swing flap motor abnormality",
+	"A8": "Indoor power supply voltage abnormality",
+	"A9": "Indoor electronic expansion valve abnormality",
+	"AF": "Humidifier or drain system abnormality",
+	"AH": "Air cleaner or streamer unit abnormality",
+	"AJ": "Indoor unit capacity setting abnormality",
+	"C4": "Indoor heat exchanger thermistor abnormality",
+	"C5": "Indoor heat exchanger middle thermistor abnormality",
+	"C7": "Front panel drive motor abnormality",
+	"C9": "Indoor suction air thermistor abnormality",
+	"CA": "Indoor discharge air thermistor abnormality",
+	"CC": "Humidity sensor abnormality",
+	"CJ": "Remote controller thermistor abnormality",
+	"E0": "Outdoor safety device activated",
+	"E1": "Outdoor unit PCB defect",
+	"E3": "High pressure switch activated",
+	"E4": "Low pressure abnormality",
+	"E5": "Compressor overload or lock",
+	"E6": "Compressor start-up failure",
+	"E7": "Outdoor fan motor abnormality",
+	"E8": "Input overcurrent",
+	"E9": "Outdoor electronic expansion valve abnormality",
+	"EA": "Four-way valve switching abnormality",
+	"F3": "Discharge pipe temperature too high",
+	"F6": "High pressure in cooling or refrigerant overcharge",
+	"H0": "Compressor sensor system abnormality",
+	"H3": "High pressure switch abnormality",
+	"H6": "Compressor position sensor abnormality",
+	"H7": "Outdoor fan motor signal abnormality",
+	"H8": "Compressor current sensor abnormality",
+	"H9": "Outdoor air thermistor abnormality",
+	"J3": "Discharge pipe thermistor abnormality",
+	"J5": "Suction pipe thermistor abnormality",
+	"J6": "Outdoor heat exchanger thermistor abnormality",
+	"J8": "Liquid pipe thermistor abnormality",
+	"J9": "Gas pipe thermistor abnormality",
+	"L3": "Electrical box temperature too high",
+	"L4": "Inverter radiation fin temperature too high",
+	"L5": "Inverter compressor overcurrent",
+	"L8": "Inverter current abnormality",
+	"L9": "Compressor start-up prevention",
+	"LC": "Communication error between inverter and outdoor control PCB",
+	"P1": "Power supply phase imbalance",
+	"P4": "Radiation fin thermistor abnormality",
+	"PJ": "Outdoor unit capacity setting abnormality",
+	"U0": "Refrigerant shortage",
+	"U1": "Reverse phase or open phase",
+	"U2": "Power supply voltage abnormality",
+	"U3": "Test run not completed",
+	"U4": "Communication error between indoor and outdoor units",
+	"U5": "Communication error between indoor unit and remote controller",
+	"U7": "Communication error between outdoor units",
+	"U8": "Communication error between main and sub remote controllers",
+	"UA": "Indoor and outdoor unit combination mismatch",
+	"UF": "Refrigerant piping or wiring mismatch",
+	"UH": "Central control address abnormality",
+}
+
+// Fault is a fault or warning code reported by a unit
+type Fault struct {
+	Code        string
+	Description string
+	// Warning is set for codes the unit reports as a warning, the unit
+	// keeps running
+	Warning bool
+}
+
+// DescribeFault returns the description of a fault code, "Unknown fault"
+// for codes missing from FaultDescriptions
+func DescribeFault(code string) string {
+	if description, exists := FaultDescriptions[strings.ToUpper(code)]; exists {
+		return description
+	}
+	return "Unknown fault"
+}
+
+// FaultReporter is implemented by appliances reporting fault codes:
+// BRP069, BRP072C and BRP084
+type FaultReporter interface {
+	// SupportsFaults reports whether the unit reports fault codes
+	SupportsFaults() bool
+	// Faults returns the faults of the last status update
+	Faults() ([]Fault, error)
+}
+
+// supportsFaults reports whether the unit reported an error (err) or
+// warning (warn) code in its last status update
+func (b *BaseAppliance) supportsFaults() bool {
+	return b.Values.Has("err") || b.Values.Has("warn")
+}
+
+// faults returns the error and warning codes of the last status update,
+// none when the unit is healthy
+func (b *BaseAppliance) faults() ([]Fault, error) {
+	if !b.supportsFaults() {
+		return nil, NewCapabilityError("fault codes not reported by this device", nil)
+	}
+
+	var faults []Fault
+	for _, key := range []string{"err", "warn"} {
+		value, _ := b.Values.Get(key)
+		if code := faultCode(value); code != "" {
+			faults = append(faults, Fault{Code: code, Description: DescribeFault(code), Warning: key == "warn"})
+		}
+	}
+	return faults, nil
+}
+
+// faultCode normalizes a reported code, empty when it means no fault
+func faultCode(value string) string {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if value == "" || value == "-" || value == "--" {
+		return ""
+	}
+	if n, err := strconv.Atoi(value); err == nil && n == 0 {
+		return ""
+	}
+	return value
+}
+
+// decodeFaultCode decodes a BRP084 fault attribute, the code's characters
+// in hex ("5534" is U4) or zeros when there is no fault
+func decodeFaultCode(pv string) string {
+	if strings.Trim(pv, "0") == "" {
+		return "0"
+	}
+	text, err := hex.DecodeString(pv)
+	if err != nil {
+		return pv
+	}
+	code := strings.TrimRight(string(text), "\x00 ")
+	for _, c := range code {
+		if (c < '0' || c > '9') && (c < 'A' || c > 'Z') {
+			return pv
+		}
+	}
+	return code
+}
+
+// FaultEvent is a fault appearing on or clearing from a unit, or a failed
+// poll
+type FaultEvent struct {
+	Appliance Appliance
+	Fault     Fault
+	// Cleared is set when the fault is no longer reported
+	Cleared bool
+	Time    time.Time
+	// Err is set, with no fault, when the unit could not be polled
+	Err error
+}
+
+// WatchFaults polls the appliance every interval (DefaultFaultPollInterval
+// if zero) and sends an event whenever a fault appears or clears, starting
+// with the faults present on the first poll. The channel is closed once ctx
+// is done, or after an event carrying a CapabilityError if the appliance
+// does not report faults.
+func WatchFaults(ctx context.Context, appliance Appliance, interval time.Duration) <-chan FaultEvent {
+	if interval <= 0 {
+		interval = DefaultFaultPollInterval
+	}
+
+	events := make(chan FaultEvent)
+	go func() {
+		defer close(events)
+
+		send := func(event FaultEvent) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		active := make(map[Fault]bool)
+		for {
+			faults, err := pollFaults(ctx, appliance)
+			now := time.Now()
+			if err != nil {
+				var capabilityErr *CapabilityError
+				if !send(FaultEvent{Appliance: appliance, Time: now, Err: err}) || errors.As(err, &capabilityErr) {
+					return
+				}
+			} else {
+				current := make(map[Fault]bool, len(faults))
+				for _, fault := range faults {
+					current[fault] = true
+					if !active[fault] && !send(FaultEvent{Appliance: appliance, Fault: fault, Time: now}) {
+						return
+					}
+				}
+				for fault := range active {
+					if !current[fault] && !send(FaultEvent{Appliance: appliance, Fault: fault, Cleared: true, Time: now}) {
+						return
+					}
+				}
+				active = current
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return events
+}
+
+// pollFaults updates the status of the appliance and returns its faults
+func pollFaults(ctx context.Context, appliance Appliance) ([]Fault, error) {
+	reporter, ok := appliance.(FaultReporter)
+	if !ok {
+		return nil, NewCapabilityError("fault codes not supported by this device type", nil)
+	}
+	if err := appliance.UpdateStatus(ctx); err != nil {
+		return nil, err
+	}
+	return reporter.Faults()
+}
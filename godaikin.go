@@ -5,12 +5,18 @@ import (
 	"crypto/tls"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type ClientOption func(*DaikinClient)
 
 type DaikinClient struct {
-	logger Logger
+	logger         Logger
+	tracerProvider trace.TracerProvider
 }
 
 func NewClient(opts ...ClientOption) *DaikinClient {
@@ -19,7 +25,9 @@ func NewClient(opts ...ClientOption) *DaikinClient {
 	}
 
 	for _, opt := range opts {
-		opt(client)
+		if opt != nil {
+			opt(client)
+		}
 	}
 
 	return client
@@ -27,17 +35,27 @@ func NewClient(opts ...ClientOption) *DaikinClient {
 
 func WithLogger(slogger *slog.Logger) ClientOption {
 	return func(c *DaikinClient) {
-		c.logger = NewSlogAdapter(slogger)
+		c.logger = NewRedactingLogger(NewSlogAdapter(slogger))
 	}
 }
 
-func (c *DaikinClient) Connect(deviceIP string, options ...Option) (Appliance, error) {
+func (c *DaikinClient) Connect(deviceIP string, options ...Option) (device Appliance, err error) {
+	config := &Config{TracerProvider: c.tracerProvider}
+	for _, opt := range options {
+		opt(config)
+	}
+
+	ctx, span := newTracer(config.TracerProvider).Start(context.Background(), "daikin.connect",
+		trace.WithAttributes(attrDeviceIP.String(deviceIP)))
+	defer func() { endSpan(span, err) }()
+
 	c.logger.Info("Connecting to Daikin device", "ip", deviceIP)
-	device, err := CreateDaikinDevice(deviceIP, c.logger, options...)
+	device, err = createDaikinDevice(ctx, deviceIP, c.logger, config, nil)
 	if err != nil {
 		c.logger.Error("Failed to connect to device", "ip", deviceIP, "error", err)
 		return nil, err
 	}
+	span.SetAttributes(attrDeviceType.String(device.GetDeviceType()))
 	c.logger.Info("Successfully connected to device", "ip", deviceIP, "type", device.GetDeviceType())
 	return device, nil
 }
@@ -69,6 +87,29 @@ type Config struct {
 	Key        string
 	UUID       string
 	SSLContext *tls.Config
+	Transport  http.RoundTripper
+
+	// Credentials, if set, supplies the password, key and UUID at the time
+	// they are used
+	Credentials CredentialProvider
+
+	TracerProvider trace.TracerProvider
+
+	// MeterProvider, if set, receives the adapter health metrics
+	MeterProvider metric.MeterProvider
+
+	// MaxResponseSize limits response bodies, DefaultMaxResponseSize if zero
+	MaxResponseSize int64
+
+	// ClockLocation is the time zone of the unit's clock, time.UTC if nil
+	ClockLocation *time.Location
+
+	// DisableClockSync stops Init from setting the unit's clock
+	DisableClockSync bool
+
+	// skipRegistration stops BRP072C detection from registering the
+	// terminal, for Diagnose
+	skipRegistration bool
 }
 
 type Option func(*Config)
@@ -96,3 +137,42 @@ func WithSSLContext(sslContext *tls.Config) Option {
 		c.SSLContext = sslContext
 	}
 }
+
+// WithTransport routes every request made by the device through the given
+// RoundTripper, e.g. a RecordingTransport or a ReplayTransport
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Config) {
+		c.Transport = transport
+	}
+}
+
+// WithMaxResponseSize sets the largest response body accepted from the
+// device; longer responses fail with a ParseError
+func WithMaxResponseSize(size int64) Option {
+	return func(c *Config) {
+		c.MaxResponseSize = size
+	}
+}
+
+// WithClockLocation sets the time zone the unit's clock is kept in
+func WithClockLocation(location *time.Location) Option {
+	return func(c *Config) {
+		c.ClockLocation = location
+	}
+}
+
+// WithoutClockSync stops Init from setting the unit's clock, for units
+// whose clock is managed elsewhere
+func WithoutClockSync() Option {
+	return func(c *Config) {
+		c.DisableClockSync = true
+	}
+}
+
+// WithMetrics exports the adapter health (request count, error rate,
+// latency percentiles and Wi-Fi signal) as OpenTelemetry gauges
+func WithMetrics(provider metric.MeterProvider) Option {
+	return func(c *Config) {
+		c.MeterProvider = provider
+	}
+}
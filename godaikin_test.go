@@ -1,10 +1,29 @@
 package godaikin
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/embedded"
+	"go.opentelemetry.io/otel/metric/noop"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestParseResponse(t *testing.T) {
@@ -166,10 +185,10 @@ func TestBaseApplianceProperties(t *testing.T) {
 
 	// Test power state
 	base.Values.Set("pow", "1")
-	assert.True(t, base.GetPowerState())
+	assert.Equal(t, "1", base.GetPowerState())
 
 	base.Values.Set("pow", "0")
-	assert.False(t, base.GetPowerState())
+	assert.Equal(t, "0", base.GetPowerState())
 
 	// Test mode with power off
 	base.Values.Set("mode", "3")
@@ -433,3 +452,1721 @@ func TestBaseApplianceDefaultMethods(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "SetAdvancedMode not supported")
 }
+
+// fakeBRP069 is a fake BRP069 adapter serving fakeBRP069Resources, some of
+// them replaced. Requests to a set_ resource are recorded and answered
+// ret=OK unless the adapter holds another answer; other resources it does
+// not hold are answered with the missing status, 404 by default.
+type fakeBRP069 struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	resources map[string]string
+	sent      map[string][]map[string]string
+	missing   int
+}
+
+// fakeBRP069Adapter returns the handler of a fake BRP069 adapter with the
+// given resources replaced, for tests that start their own server
+func fakeBRP069Adapter(overrides map[string]string) *fakeBRP069 {
+	resources := make(map[string]string, len(fakeBRP069Resources)+len(overrides))
+	for path, body := range fakeBRP069Resources {
+		resources[path] = body
+	}
+	for path, body := range overrides {
+		resources[path] = body
+	}
+	return &fakeBRP069{
+		resources: resources,
+		sent:      make(map[string][]map[string]string),
+		missing:   http.StatusNotFound,
+	}
+}
+
+// newFakeBRP069 starts a fake BRP069 adapter with the given resources
+// replaced
+func newFakeBRP069(t *testing.T, overrides map[string]string) *fakeBRP069 {
+	t.Helper()
+	adapter := fakeBRP069Adapter(overrides)
+	adapter.Server = httptest.NewServer(adapter)
+	t.Cleanup(adapter.Close)
+	return adapter
+}
+
+func (f *fakeBRP069) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	body, exists := f.resources[path]
+	if strings.Contains(path, "/set_") {
+		params := make(map[string]string)
+		for key := range r.URL.Query() {
+			params[key] = r.URL.Query().Get(key)
+		}
+		f.sent[path] = append(f.sent[path], params)
+		if !exists {
+			body, exists = "ret=OK", true
+		}
+	}
+	if !exists {
+		http.Error(w, http.StatusText(f.missing), f.missing)
+		return
+	}
+	fmt.Fprint(w, body)
+}
+
+// set replaces the answer to a resource
+func (f *fakeBRP069) set(path, body string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.resources[path] = body
+}
+
+// requests returns the parameters of each request to a set_ resource, in
+// order
+func (f *fakeBRP069) requests(path string) []map[string]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]map[string]string(nil), f.sent[path]...)
+}
+
+// last returns the parameters of the last request to a set_ resource, nil
+// if there was none
+func (f *fakeBRP069) last(path string) map[string]string {
+	requests := f.requests(path)
+	if len(requests) == 0 {
+		return nil
+	}
+	return requests[len(requests)-1]
+}
+
+// controls returns the parameters of each set_control_info request, in order
+func (f *fakeBRP069) controls() []map[string]string {
+	return f.requests("aircon/set_control_info")
+}
+
+var fakeBRP069Resources = map[string]string{
+	"common/basic_info":       "ret=OK,type=aircon,reg=eu,ver=1_2_54,pow=1,name=%4c%69%76%69%6e%67,mac=AABBCCDDEEFF",
+	"common/get_datetime":     "ret=OK,sta=2,cur=2024/1/15 13:05:12",
+	"aircon/get_sensor_info":  "ret=OK,htemp=22.5,hhum=-,otemp=8.0,err=0,cmpfreq=20",
+	"aircon/get_control_info": "ret=OK,pow=1,mode=3,stemp=24.0,shum=0,f_rate=A,f_dir=0,dt3=24.0,dh3=0,dfr3=A",
+	"aircon/get_model_info":   "ret=OK,model=NOTSUPPORT,type=N,pv=2,cpv=2,mid=NA,en_frate=1,en_fdir=1",
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	server := newFakeBRP069(t, nil)
+	deviceID := strings.TrimPrefix(server.URL, "http://")
+
+	recorder := NewRecordingTransport(nil)
+	recorded, err := CreateDaikinDevice(deviceID, NoOpLogger{}, WithTransport(recorder))
+	assert.NoError(t, err)
+	assert.Equal(t, "BRP069", recorded.GetDeviceType())
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	assert.NoError(t, recorder.Save(path))
+	server.Close()
+
+	cassette, err := LoadCassette(path)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, cassette.Interactions)
+
+	replayed, err := CreateDaikinDevice(deviceID, NoOpLogger{}, WithTransport(NewReplayTransport(cassette)))
+	assert.NoError(t, err)
+	assert.Equal(t, "BRP069", replayed.GetDeviceType())
+	// The MAC address is redacted from the recording
+	values := recorded.GetValues().All()
+	assert.Equal(t, "AABBCCDDEEFF", values["mac"])
+	values["mac"] = redactedValue
+	assert.Equal(t, values, replayed.GetValues().All())
+
+	// BRP072C keeps its TLS settings under the recorder
+	adapter := httptest.NewTLSServer(fakeBRP069Adapter(map[string]string{
+		"common/register_terminal": "ret=OK",
+		"common/get_wifi_setting":  "ret=OK,ssid=Home,security=mixed,key=%73%65%63%72%65%74,link=1",
+	}))
+	deviceID = strings.TrimPrefix(adapter.URL, "https://")
+
+	recorder = NewRecordingTransport(nil)
+	recorded, err = CreateDaikinDevice(deviceID, NoOpLogger{}, WithKey("secret-key"), WithTransport(recorder))
+	assert.NoError(t, err)
+	assert.Equal(t, "BRP072C", recorded.GetDeviceType())
+	_, err = recorded.(*DaikinBRP072C).GetWifiSettings(context.Background())
+	assert.NoError(t, err)
+	adapter.Close()
+
+	cassette = recorder.Cassette()
+	encoded, err := json.Marshal(cassette)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(encoded), "secret-key")
+	assert.NotContains(t, string(encoded), "%73%65%63%72%65%74")
+	assert.NotContains(t, string(encoded), "AABBCCDDEEFF")
+
+	replayed, err = CreateDaikinDevice(deviceID, NoOpLogger{}, WithKey("other-key"), WithTransport(NewReplayTransport(cassette)))
+	assert.NoError(t, err)
+	assert.Equal(t, "BRP072C", replayed.GetDeviceType())
+	settings, err := replayed.(*DaikinBRP072C).GetWifiSettings(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "Home", settings.SSID)
+}
+
+func TestRecordingRedactsSecrets(t *testing.T) {
+	server := newFakeBRP069(t, map[string]string{"ac.cgi": "ret=OK,opmode=1"})
+
+	recorder := NewRecordingTransport(nil)
+	device := NewDaikinSkyFi("127.0.0.1", "secret-password", nil)
+	device.BaseURL = server.URL
+	device.HTTPClient.Transport = recorder
+	device.Headers["X-Daikin-uuid"] = "secret-uuid"
+
+	_, _ = device.getResource(context.Background(), "ac.cgi", map[string]string{"pass": "secret-password"})
+
+	cassette := recorder.Cassette()
+	assert.Len(t, cassette.Interactions, 1)
+	request := cassette.Interactions[0].Request
+	assert.Equal(t, "pass=REDACTED", request.Query)
+	assert.Equal(t, "REDACTED", request.Header["X-Daikin-Uuid"])
+
+	// Replay matches regardless of the secret used
+	replay := NewReplayTransport(cassette)
+	device.HTTPClient.Transport = replay
+	device.Password = "other-password"
+	data, err := device.getResource(context.Background(), "ac.cgi", map[string]string{"pass": "other-password"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"opmode": "1"}, data)
+}
+
+func TestDiagnose(t *testing.T) {
+	server := newFakeBRP069(t, nil)
+	deviceID := strings.TrimPrefix(server.URL, "http://")
+
+	var output strings.Builder
+	report, err := Diagnose(context.Background(), deviceID, WithRedaction(), WithDiagnosticsOutput(&output))
+	assert.NoError(t, err)
+	assert.Equal(t, "BRP069", report.DeviceType)
+
+	// Probes follow CreateDaikinDevice's order and stop at the first success
+	assert.Len(t, report.Probes, 2)
+	assert.Equal(t, "BRP084", report.Probes[0].Name)
+	assert.False(t, report.Probes[0].Success)
+	assert.Contains(t, report.Probes[0].Error, "not a BRP084 device")
+	assert.Equal(t, "BRP069", report.Probes[1].Name)
+	assert.True(t, report.Probes[1].Success)
+
+	statuses := make(map[string]int)
+	for _, exchange := range report.Resources {
+		statuses[exchange.Family+" "+exchange.URL] = exchange.Status
+		assert.NotContains(t, exchange.Body, "AABBCCDDEEFF")
+	}
+	assert.Equal(t, http.StatusOK, statuses["BRP069 "+server.URL+"/common/basic_info"])
+	assert.Equal(t, http.StatusNotFound, statuses["BRP069 "+server.URL+"/common/get_wifi_setting"])
+	assert.Equal(t, http.StatusNotFound, statuses["AirBase "+server.URL+"/skyfi/aircon/get_zone_setting"])
+
+	assert.Contains(t, output.String(), `"device_type": "BRP069"`)
+	assert.Contains(t, output.String(), "mac=REDACTED")
+
+	// Diagnostics never change the device
+	var requested []string
+	adapter := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/")
+		requested = append(requested, path)
+		if body, exists := fakeBRP069Resources[path]; exists {
+			fmt.Fprint(w, body)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer adapter.Close()
+	report, err = Diagnose(context.Background(), strings.TrimPrefix(adapter.URL, "https://"),
+		WithDeviceOptions(WithKey("secret-key")))
+	assert.NoError(t, err)
+	assert.Equal(t, "BRP072C", report.DeviceType)
+	assert.Contains(t, requested, "common/basic_info")
+	assert.NotContains(t, requested, "common/register_terminal")
+	assert.NotContains(t, requested, "common/notify_date_time")
+}
+
+func TestTracing(t *testing.T) {
+	server := newFakeBRP069(t, nil)
+	deviceID := strings.TrimPrefix(server.URL, "http://")
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	client := NewClient(WithTracerProvider(provider))
+	device, err := client.Connect(deviceID)
+	assert.NoError(t, err)
+	assert.NoError(t, device.UpdateStatus(context.Background()))
+
+	spans := make(map[string][]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = append(spans[span.Name()], span)
+	}
+
+	assert.Len(t, spans["daikin.connect"], 1)
+	assert.Len(t, spans["daikin.detect"], 2)
+	// One from the BRP084 probe, one from the explicit call
+	assert.Len(t, spans["daikin.update_status"], 2)
+	assert.NotEmpty(t, spans["daikin.get_resource"])
+
+	// The BRP084 probe fails with a connection error on the 404
+	probe := spans["daikin.detect"][0]
+	assert.Equal(t, codes.Error, probe.Status().Code)
+	assert.Contains(t, probe.Attributes(), attrProbe.String("BRP084"))
+	assert.Contains(t, probe.Attributes(), attrErrorType.String("connection"))
+
+	// The BRP084 probe's multireq records the resources it read
+	var targets []string
+	for _, span := range spans["daikin.get_resource"] {
+		for _, attr := range span.Attributes() {
+			if attr.Key == attrTargets {
+				targets = attr.Value.AsStringSlice()
+			}
+		}
+	}
+	assert.Contains(t, targets, "/dsiot/edge/adr_0100.dgc_status")
+	assert.Contains(t, targets, "/dsiot/edge.adp_i")
+
+	connect := spans["daikin.connect"][0]
+	for _, span := range spans["daikin.get_resource"] {
+		assert.Equal(t, connect.SpanContext().TraceID(), span.SpanContext().TraceID())
+		if span.Status().Code != codes.Error {
+			assert.Contains(t, span.Attributes(), attrDeviceType.String("BRP069"))
+		}
+	}
+
+	// Commands record the ret they were answered with
+	server = newFakeBRP069(t, map[string]string{
+		"common/basic_info": fakeBRP069Resources["common/basic_info"] + ",led=1",
+		"common/set_led":    "ret=PARAM NG",
+	})
+	device, err = client.Connect(strings.TrimPrefix(server.URL, "http://"))
+	assert.NoError(t, err)
+	assert.Error(t, device.(*DaikinBRP069).SetLED(context.Background(), false))
+
+	ended := recorder.Ended()
+	command := ended[len(ended)-1]
+	assert.Equal(t, codes.Error, command.Status().Code)
+	assert.Contains(t, command.Attributes(), attrResourcePath.String("common/set_led"))
+	assert.Contains(t, command.Attributes(), attrRet.String("PARAM NG"))
+}
+
+func TestRedaction(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := NewRedactingLogger(NewSlogAdapter(slog.New(slog.NewTextHandler(&buffer, &slog.HandlerOptions{Level: slog.LevelDebug}))))
+
+	logger.Debug("Making HTTP request", "params", map[string]string{"pass": "hunter2", "mode": "3"}, "key", "abc123")
+	logger.Info("Request failed", "error", fmt.Errorf(`Get "http://10.0.0.2/ac.cgi?pass=hunter2": timeout`))
+	assert.NotContains(t, buffer.String(), "hunter2")
+	assert.NotContains(t, buffer.String(), "abc123")
+	assert.Contains(t, buffer.String(), "mode:3")
+
+	err := NewConnectionError("failed to make request", fmt.Errorf(`Get "https://10.0.0.2/common/register_terminal?key=abc123": EOF`))
+	assert.NotContains(t, err.Error(), "abc123")
+	assert.Contains(t, err.Error(), "key=REDACTED")
+}
+
+func TestCredentialProviderRotation(t *testing.T) {
+	var passwords []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		passwords = append(passwords, r.URL.Query().Get("pass"))
+		fmt.Fprint(w, "ret=OK,opmode=1")
+	}))
+	defer server.Close()
+
+	current := "first"
+	provider := CredentialsFunc(func(context.Context) (Credentials, error) {
+		return Credentials{Password: current}, nil
+	})
+
+	deviceID := strings.TrimPrefix(server.URL, "http://")
+	device, err := CreateDaikinDevice(deviceID, nil, WithCredentialProvider(provider))
+	assert.NoError(t, err)
+	assert.Equal(t, "SkyFi", device.GetDeviceType())
+
+	current = "second"
+	assert.NoError(t, device.Init(context.Background()))
+
+	assert.Equal(t, "first", passwords[0])
+	assert.Equal(t, "second", passwords[len(passwords)-1])
+}
+
+func TestFileCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"key":"k1","uuid":"u1"}`), 0o600))
+
+	provider := NewFileCredentials(path)
+	credentials, err := provider.Credentials(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, Credentials{Key: "k1", UUID: "u1"}, credentials)
+
+	t.Setenv("TEST_DAIKIN_PASSWORD", "p1")
+	credentials, err = EnvCredentials{Prefix: "TEST_DAIKIN"}.Credentials(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "p1", credentials.Password)
+}
+
+func TestResponseDecoding(t *testing.T) {
+	days := make([]string, 366)
+	for i := range days {
+		days[i] = "12"
+	}
+	history := strings.Join(days, "/")
+	server := newFakeBRP069(t, map[string]string{
+		"aircon/get_year_power_ex": "ret=OK,curr_year_heat=" + history + ",zone_name=%4c%69%76%69%6e%67;Bed%201",
+	})
+	ctx := context.Background()
+
+	device := NewDaikinBRP069(strings.TrimPrefix(server.URL, "http://"), nil)
+	device.BaseURL = server.URL
+	data, err := device.getResource(ctx, "aircon/get_year_power_ex", nil)
+	assert.NoError(t, err)
+	assert.Len(t, decodeList(data["curr_year_heat"], "/"), 366)
+	assert.Equal(t, []string{"Living", "Bed 1"}, decodeList(data["zone_name"], ";"))
+	assert.Equal(t, []string{"a/b", "c+d"}, decodeList("a%2fb/c+d", "/"))
+	assert.Equal(t, []string{"Living", "Bed 1"}, decodeZoneList("%4c%69%76%69%6e%67%3bBed%201"))
+
+	device.MaxResponseSize = 1024
+	_, err = device.getResource(ctx, "aircon/get_year_power_ex", nil)
+	assert.ErrorContains(t, err, "response truncated")
+	var parseErr *ParseError
+	assert.ErrorAs(t, err, &parseErr)
+
+	skyfi := NewDaikinSkyFi("127.0.0.1", "pass", nil)
+	values := skyfi.parseSkyFiResponse("opmode=1&settemp=21.0&fanspeed=2&fanflags=3")
+	assert.Equal(t, "1", values["pow"])
+	assert.Equal(t, "21.0", values["stemp"])
+	assert.Equal(t, "6", values["f_rate"])
+}
+
+func TestDecodeValues(t *testing.T) {
+	server := newFakeBRP069(t, nil)
+
+	device, err := CreateDaikinDevice(strings.TrimPrefix(server.URL, "http://"), nil)
+	assert.NoError(t, err)
+
+	decoder, ok := device.(ValuesDecoder)
+	assert.True(t, ok)
+
+	var basic BasicInfo
+	assert.NoError(t, decoder.DecodeValues(&basic))
+	assert.Equal(t, "Living", basic.Name)
+	assert.Equal(t, "AABBCCDDEEFF", basic.MAC)
+
+	var control ControlInfo
+	assert.NoError(t, decoder.DecodeValues(&control))
+	assert.True(t, control.Power)
+	assert.Equal(t, "24.0", control.TargetTemperature)
+
+	var sensor SensorInfo
+	assert.NoError(t, decoder.DecodeValues(&sensor))
+	assert.Equal(t, 22.5, *sensor.InsideTemperature)
+	assert.Nil(t, sensor.InsideHumidity)
+}
+
+// fakeBRP084Responses is a multireq response of a unit cooling at 24°C with
+// a 1 to 5 fan, vertical swing, econo and streamer, with -5°C outside and
+// an A5 warning
+const fakeBRP084Responses = `{"responses":[
+{"fr":"/dsiot/edge/adr_0100.dgc_status","rsc":2000,"pc":{"pn":"dgc_status","pch":[{"pn":"e_1002","pch":[
+	{"pn":"e_A002","pch":[{"pn":"p_01","pt":3,"pv":"01"}]},
+	{"pn":"e_A00B","pch":[{"pn":"p_01","pt":3,"pv":"1600"},{"pn":"p_02","pt":3,"pv":"32"}]},
+	{"pn":"e_A003","pch":[{"pn":"p_01","pt":3,"pv":"0000"},{"pn":"p_02","pt":3,"pv":"4135"}]},
+	{"pn":"e_3001","pch":[{"pn":"p_01","pt":3,"pv":"0200"},{"pn":"p_02","pt":3,"pv":"30","md":{"pt":"s","st":1}},
+		{"pn":"p_09","pt":3,"pv":"0500"},{"pn":"p_05","pt":3,"pv":"0F0000"},{"pn":"p_06","pt":3,"pv":"000000"}]},
+	{"pn":"e_3003","pch":[{"pn":"p_01","pt":3,"pv":"00"},{"pn":"p_02","pt":3,"pv":"01"},
+		{"pn":"p_05","pt":3,"pv":"01"},{"pn":"p_07","pt":3,"pv":"00"}]}]}]}},
+{"fr":"/dsiot/edge/adr_0200.dgc_status","rsc":2000,"pc":{"pn":"dgc_status","pch":[{"pn":"e_1003","pch":[
+	{"pn":"e_A00D","pch":[{"pn":"p_01","pt":3,"pv":"F6FF"}]}]}]}},
+{"fr":"/dsiot/edge/adr_0100.i_power.week_power","rsc":2000,"pc":{"pn":"week_power","pch":[
+	{"pn":"today_runtime","pt":3,"pv":"42"},{"pn":"datas","pt":3,"pv":[1,2,3]}]}},
+{"fr":"/dsiot/edge.adp_i","rsc":2000,"pc":{"pn":"adp_i","pch":[{"pn":"mac","pt":3,"pv":"AABBCCDDEEFF"},{"pn":"name","pt":3,"pv":"Office"},{"pn":"ver","pt":3,"pv":"2.8.0"},{"pn":"ssid","pt":3,"pv":"Daikin+AP 100%"}]}},
+{"fr":"/dsiot/edge.adp_d","rsc":2000,"pc":{"pn":"adp_d","pch":[{"pn":"cloud","pt":2,"pv":"01"},{"pn":"rssi","pt":3,"pv":"-61"},{"pn":"uptime","pt":3,"pv":"3600"}]}}]}`
+
+// newFakeBRP084 starts a fake BRP084 adapter answering reads with
+// fakeBRP084Responses and recording the write requests it receives
+func newFakeBRP084(t *testing.T, writes *[]MultiRequest) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/dsiot/multireq" {
+			http.NotFound(w, r)
+			return
+		}
+
+		var request MultiRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(request.Requests) > 0 && request.Requests[0].Op == MultiReqOpWrite {
+			*writes = append(*writes, request)
+			fmt.Fprint(w, `{"responses":[{"fr":"/dsiot/edge/adr_0100.dgc_status","rsc":2004}]}`)
+			return
+		}
+		fmt.Fprint(w, fakeBRP084Responses)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestBRP084Status(t *testing.T) {
+	var writes []MultiRequest
+	server := newFakeBRP084(t, &writes)
+
+	device, err := CreateDaikinDevice(strings.TrimPrefix(server.URL, "http://"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "BRP084", device.GetDeviceType())
+
+	values := device.GetValues().All()
+	assert.Equal(t, "1", values["pow"])
+	assert.Equal(t, "cool", values["mode"])
+	assert.Equal(t, "22.0", values["htemp"])
+	assert.Equal(t, "-5.0", values["otemp"])
+	assert.Equal(t, "50", values["hhum"])
+	assert.Equal(t, "24.0", values["stemp"])
+	assert.Equal(t, "3", values["f_rate"])
+	assert.Equal(t, "vertical", values["f_dir"])
+	assert.Equal(t, "42", values["today_runtime"])
+	assert.Equal(t, "1/2/3", values["datas"])
+	assert.Equal(t, "AA:BB:CC:DD:EE:FF", device.GetMAC())
+
+	err = device.Set(context.Background(), map[string]string{"stemp": "22.5", "f_dir": "both"})
+	assert.NoError(t, err)
+	assert.Len(t, writes, 1)
+	assert.Len(t, writes[0].Requests, 1)
+
+	body, _ := json.Marshal(writes[0].Requests[0].PC)
+	assert.JSONEq(t, `{"pn":"dgc_status","pch":[{"pn":"e_1002","pch":[{"pn":"e_3001","pch":[
+		{"pn":"p_02","pv":"2D"},{"pn":"p_05","pv":"0F0000"},{"pn":"p_06","pv":"0F0000"}]}]}]}`, string(body))
+}
+
+func TestBRP084Values(t *testing.T) {
+	value, err := decodePVUint("0A00")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), value)
+
+	temperature, err := decodePVTemperature("F6FF", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, -5.0, temperature)
+
+	assert.Equal(t, "30", encodePVTemperature(24, 2, 1))
+	assert.Equal(t, "F6FF", encodePVTemperature(-5, 2, 2))
+	assert.Equal(t, "0300", encodePVUint(3, 2))
+
+	bits, err := decodePVBits("0F0000")
+	assert.NoError(t, err)
+	assert.True(t, bits.has(0x0F))
+	assert.False(t, bits.has(0xF0))
+
+	_, err = decodePVUint("zz")
+	var parseErr *ParseError
+	assert.ErrorAs(t, err, &parseErr)
+
+	path, err := ParseAttributePath("/dsiot/edge/adr_0100.dgc_status/dgc_status/e_1002/e_A002/p_01")
+	assert.NoError(t, err)
+	assert.Equal(t, brp084Power, path)
+	_, err = ParseAttributePath("/dsiot/edge/adr_0100.dgc_status")
+	assert.Error(t, err)
+	_, err = ParseAttributePath("/dsiot/edge/adr_0100.dgc_status/dgc_status")
+	assert.Error(t, err)
+	_, err = ParseAttributePath("/dsiot/edge/adr_0100.dgc_status/dgc_status//p_01")
+	assert.Error(t, err)
+}
+
+func TestBRP084Attributes(t *testing.T) {
+	var writes []MultiRequest
+	server := newFakeBRP084(t, &writes)
+	ctx := context.Background()
+
+	device := NewDaikinBRP084("127.0.0.1", nil)
+	device.URL = server.URL + "/dsiot/multireq"
+
+	humidity, err := ParseAttributePath("/dsiot/edge/adr_0100.dgc_status/dgc_status/e_1002/e_A00B/p_02")
+	assert.NoError(t, err)
+	unknown := indoorAttribute("e_3003", "p_2C")
+
+	attributes, err := device.ReadAttributes(ctx, humidity, brp084OutdoorTemperature, brp084MAC, unknown)
+	assert.NoError(t, err)
+	assert.Len(t, attributes, 3)
+	property := attributes[humidity]
+	pv, err := property.Hex()
+	assert.NoError(t, err)
+	assert.Equal(t, "32", pv)
+	assert.NotContains(t, attributes, unknown)
+
+	err = device.WriteAttributes(ctx, map[AttributePath]string{
+		unknown:                           "01",
+		indoorAttribute("e_3003", "p_01"): "00",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, writes, 1)
+	body, _ := json.Marshal(writes[0])
+	assert.JSONEq(t, `{"requests":[{"op":3,"to":"/dsiot/edge/adr_0100.dgc_status","pc":{"pn":"dgc_status","pch":[
+		{"pn":"e_1002","pch":[{"pn":"e_3003","pch":[{"pn":"p_01","pv":"00"},{"pn":"p_2C","pv":"01"}]}]}]}}]}`, string(body))
+
+	// A single-segment PN has no resource root to hang the value from
+	err = device.WriteAttributes(ctx, map[AttributePath]string{
+		{To: "/dsiot/edge/adr_0100.dgc_status", PN: "p_01"}: "00",
+		unknown: "01",
+	})
+	assert.Error(t, err)
+	assert.Len(t, writes, 1)
+}
+
+func TestBRP084SpecialModes(t *testing.T) {
+	var writes []MultiRequest
+	server := newFakeBRP084(t, &writes)
+	ctx := context.Background()
+
+	device, err := CreateDaikinDevice(strings.TrimPrefix(server.URL, "http://"), nil)
+	assert.NoError(t, err)
+	assert.True(t, device.SupportsAdvancedModes())
+	assert.True(t, device.SupportsAwayMode())
+
+	values := device.GetValues().All()
+	assert.Equal(t, "econo streamer", values["adv"])
+	assert.Equal(t, "0", values["en_powerful"])
+	assert.Equal(t, "1", values["en_streamer"])
+	assert.Equal(t, "0", values["en_hol"])
+
+	assert.NoError(t, device.SetAdvancedMode(ctx, "powerful", "on"))
+	assert.NoError(t, device.SetStreamer(ctx, "off"))
+	assert.NoError(t, device.SetHoliday(ctx, "on"))
+	assert.Len(t, writes, 3)
+
+	written := func(request MultiRequest) string {
+		section := request.Requests[0].PC.Children[0].Children[0]
+		return section.Name + "/" + section.Children[0].Name + "=" + string(section.Children[0].Value)
+	}
+	assert.Equal(t, `e_3003/p_01="01"`, written(writes[0]))
+	assert.Equal(t, `e_3003/p_05="00"`, written(writes[1]))
+	assert.Equal(t, `e_3003/p_07="01"`, written(writes[2]))
+
+	assert.Error(t, device.SetAdvancedMode(ctx, "turbo", "on"))
+	assert.Error(t, device.SetHoliday(ctx, "maybe"))
+
+	// Each mode must be reported on its own, not just any of them
+	device.GetValues().Delete("en_streamer")
+	var capabilityErr *CapabilityError
+	assert.ErrorAs(t, device.SetStreamer(ctx, "on"), &capabilityErr)
+	device.GetValues().Delete("en_hol")
+	assert.ErrorAs(t, device.SetHoliday(ctx, "on"), &capabilityErr)
+	assert.Len(t, writes, 3)
+}
+
+func TestBRP084Units(t *testing.T) {
+	var fixture MultiResponse
+	assert.NoError(t, json.Unmarshal([]byte(fakeBRP084Responses), &fixture))
+	byResource := make(map[string]MultiReqResponse)
+	for _, response := range fixture.Responses {
+		byResource[response.Fr] = response
+	}
+
+	// A second indoor unit sharing the outdoor unit, 2°C warmer
+	second := byResource["/dsiot/edge/adr_0100.dgc_status"]
+	second.Fr = "/dsiot/edge/adr_0101.dgc_status"
+	pc, _ := json.Marshal(second.PC)
+	second.PC = nil
+	assert.NoError(t, json.Unmarshal([]byte(strings.Replace(string(pc), `"1600"`, `"1800"`, 1)), &second.PC))
+	byResource[second.Fr] = second
+
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request MultiRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		var response MultiResponse
+		for _, req := range request.Requests {
+			to, _, _ := strings.Cut(req.To, "?")
+			requested = append(requested, to)
+			if found, exists := byResource[to]; exists {
+				response.Responses = append(response.Responses, found)
+			} else {
+				response.Responses = append(response.Responses, MultiReqResponse{Fr: to, Rsc: 4004})
+			}
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(response))
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	adapter := NewDaikinBRP084("127.0.0.1", nil)
+	adapter.URL = server.URL + "/dsiot/multireq"
+
+	units, err := adapter.Units(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []BRP084Unit{
+		{Address: "adr_0100", OutdoorAddress: "adr_0200"},
+		{Address: "adr_0101", OutdoorAddress: "adr_0200"},
+	}, units)
+	assert.Equal(t, map[string][]string{"adr_0200": {"adr_0100", "adr_0101"}}, UnitsByOutdoor(units))
+
+	requested = nil
+	appliances, err := adapter.Appliances(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, appliances, 2)
+	assert.Contains(t, requested, "/dsiot/edge/adr_0101.dgc_status")
+
+	inside, err := appliances[1].GetInsideTemperature()
+	assert.NoError(t, err)
+	assert.Equal(t, 24.0, inside)
+	outside, err := appliances[1].GetOutsideTemperature()
+	assert.NoError(t, err)
+	assert.Equal(t, -5.0, outside)
+	assert.Equal(t, adapter.HTTPClient, appliances[1].(*DaikinBRP084).HTTPClient)
+
+	// Raw attribute paths of the default unit address the unit's own
+	attributes, err := appliances[1].(*DaikinBRP084).ReadAttributes(ctx, brp084IndoorTemperature)
+	assert.NoError(t, err)
+	property := attributes[brp084IndoorTemperature]
+	pv, err := property.Hex()
+	assert.NoError(t, err)
+	assert.Equal(t, "1800", pv)
+
+	// With several outdoor units the pairing is unknown
+	outdoor := byResource["/dsiot/edge/adr_0200.dgc_status"]
+	outdoor.Fr = "/dsiot/edge/adr_0201.dgc_status"
+	byResource[outdoor.Fr] = outdoor
+	units, err = adapter.Units(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []BRP084Unit{{Address: "adr_0100"}, {Address: "adr_0101"}}, units)
+}
+
+func TestHumidityControl(t *testing.T) {
+	server := newFakeBRP069(t, map[string]string{
+		"aircon/get_control_info": "ret=OK,pow=0,mode=2,stemp=M,shum=AUTO,f_rate=A,f_dir=0,dt2=M,dh2=AUTO,dfr2=A",
+		"aircon/get_sensor_info":  "ret=OK,htemp=22.5,hhum=55,otemp=8.0,err=0,cmpfreq=20",
+	})
+
+	ctx := context.Background()
+
+	device, err := CreateDaikinDevice(strings.TrimPrefix(server.URL, "http://"), nil)
+	assert.NoError(t, err)
+
+	controller, ok := device.(HumidityController)
+	assert.True(t, ok)
+	assert.True(t, controller.SupportsHumidityControl())
+	humidity, err := controller.GetHumidity()
+	assert.NoError(t, err)
+	assert.Equal(t, 55.0, humidity)
+	target, err := controller.GetHumidityTarget()
+	assert.NoError(t, err)
+	assert.Equal(t, HumidityAuto, target)
+	_, err = controller.GetTargetHumidity()
+	assert.Error(t, err)
+
+	// Setting the target leaves the unit off
+	assert.NoError(t, controller.SetTargetHumidity(ctx, HumidityPercent(50)))
+	assert.NoError(t, controller.SetTargetHumidity(ctx, HumidityContinuous))
+	assert.Len(t, server.controls(), 2)
+	assert.Equal(t, "50", server.controls()[0]["shum"])
+	assert.Equal(t, "50", server.controls()[0]["dh2"])
+	assert.Equal(t, "0", server.controls()[0]["pow"])
+	assert.Equal(t, "CONTINUOUS", server.controls()[1]["shum"])
+	assert.Equal(t, "0", server.controls()[1]["pow"])
+	assert.Error(t, controller.SetTargetHumidity(ctx, "150"))
+
+	percent, err := controller.GetTargetHumidity()
+	assert.Error(t, err, "CONTINUOUS has no percentage")
+	assert.Zero(t, percent)
+
+	// Units without a humidity setpoint report 0 for every mode
+	var capabilityErr *CapabilityError
+	server.set("aircon/get_control_info", "ret=OK,pow=0,mode=3,stemp=24.0,shum=0,f_rate=A,f_dir=0,dt3=24.0,dh3=0,dfr3=A")
+	device, err = CreateDaikinDevice(strings.TrimPrefix(server.URL, "http://"), nil)
+	assert.NoError(t, err)
+	controller = device.(HumidityController)
+	assert.False(t, controller.SupportsHumidityControl())
+	assert.ErrorAs(t, controller.SetTargetHumidity(ctx, HumidityPercent(50)), &capabilityErr)
+	assert.Len(t, server.controls(), 2)
+
+	// BRP084 humidity setpoints are not modelled
+	var writes []MultiRequest
+	brp084, err := CreateDaikinDevice(strings.TrimPrefix(newFakeBRP084(t, &writes).URL, "http://"), nil)
+	assert.NoError(t, err)
+	_, ok = brp084.(HumidityController)
+	assert.False(t, ok)
+	assert.ErrorAs(t, brp084.(*DaikinBRP084).SetModeSetpoint(ctx, "dry", ModeSetpoint{Humidity: HumidityPercent(45)}), &capabilityErr)
+	assert.Empty(t, writes)
+}
+
+func TestModeSetpoints(t *testing.T) {
+	server := newFakeBRP069(t, map[string]string{
+		"aircon/get_control_info": "ret=OK,pow=1,mode=3,stemp=24.0,shum=0,f_rate=A,f_dir=0,dt3=24.0,dh3=0,dfr3=A,dt4=21.0,dh4=0,dfr4=5",
+	})
+
+	ctx := context.Background()
+
+	device, err := CreateDaikinDevice(strings.TrimPrefix(server.URL, "http://"), nil)
+	assert.NoError(t, err)
+	memory := device.(SetpointMemory)
+
+	setpoints := memory.GetModeSetpoints()
+	assert.Equal(t, ModeSetpoint{Temperature: "21.0", Humidity: "0", FanRate: "3"}, setpoints["heat"])
+	assert.Equal(t, "auto", setpoints["cool"].FanRate)
+	assert.Len(t, setpoints, 2)
+
+	assert.NoError(t, memory.SetModeSetpoint(ctx, "heat", ModeSetpoint{Temperature: "22.5"}))
+	assert.Len(t, server.controls(), 1)
+	assert.Equal(t, "22.5", server.controls()[0]["dt4"])
+	assert.Equal(t, "3", server.controls()[0]["mode"])
+	assert.Equal(t, "24.0", server.controls()[0]["stemp"])
+	assert.NotContains(t, server.controls()[0], "dfr4")
+
+	assert.NoError(t, memory.SetModeSetpoint(ctx, "cool", ModeSetpoint{Temperature: "23.0"}))
+	assert.Equal(t, "23.0", server.controls()[1]["stemp"])
+	assert.Error(t, memory.SetModeSetpoint(ctx, "dry", ModeSetpoint{Temperature: "23.0"}))
+
+	// Auto is remembered under whichever of its codes the unit reports
+	device.GetValues().Set("dt7", "25.0")
+	assert.Equal(t, "25.0", memory.GetModeSetpoints()["auto"].Temperature)
+
+	var writes []MultiRequest
+	brp084, err := CreateDaikinDevice(strings.TrimPrefix(newFakeBRP084(t, &writes).URL, "http://"), nil)
+	assert.NoError(t, err)
+	memory = brp084.(SetpointMemory)
+	assert.Equal(t, ModeSetpoint{Temperature: "24.0", FanRate: "3"}, memory.GetModeSetpoints()["cool"])
+
+	assert.NoError(t, memory.SetModeSetpoint(ctx, "heat", ModeSetpoint{Temperature: "21.0", FanRate: "auto"}))
+	assert.Len(t, writes, 1)
+	section := writes[0].Requests[0].PC.Children[0].Children[0]
+	assert.Equal(t, []MultiReqProperty{{Name: "p_03", Value: []byte(`"2A"`)}, {Name: "p_0A", Value: []byte(`"0A00"`)}}, section.Children)
+	assert.Equal(t, "cool", brp084.GetMode())
+}
+
+func TestLouverSettings(t *testing.T) {
+	server := newFakeBRP069(t, map[string]string{
+		"aircon/get_control_info": "ret=OK,pow=1,mode=3,stemp=24.0,shum=0,f_rate=A,f_dir=1,dt3=24.0,dh3=0,dfr3=A,dfd3=1,dt4=21.0,dh4=0,dfr4=A,dfd4=0",
+	})
+
+	ctx := context.Background()
+
+	created, err := CreateDaikinDevice(strings.TrimPrefix(server.URL, "http://"), nil)
+	assert.NoError(t, err)
+	device := created.(LouverController)
+	assert.True(t, device.SupportsLouverControl())
+
+	settings, err := device.GetLouverSettings("cool")
+	assert.NoError(t, err)
+	assert.Equal(t, LouverSettings{Vertical: LouverSwing, Horizontal: LouverStopped}, settings)
+
+	assert.NoError(t, device.SetLouverSettings(ctx, "heat", LouverSettings{Horizontal: LouverSwing}))
+	assert.Len(t, server.controls(), 1)
+	assert.Equal(t, "2", server.controls()[0]["dfd4"])
+	assert.Equal(t, "1", server.controls()[0]["f_dir"])
+
+	err = device.SetLouverSettings(ctx, "cool", LouverSettings{Vertical: LouverFixed(2), Horizontal: LouverSwing})
+	var capabilityErr *CapabilityError
+	assert.ErrorAs(t, err, &capabilityErr)
+
+	// Units with an f_dir_ud/f_dir_lr pair only set the current mode, and
+	// leave the unit off
+	server.set("aircon/get_control_info", "ret=OK,pow=0,mode=3,stemp=24.0,shum=0,f_rate=A,f_dir=0,f_dir_ud=0,f_dir_lr=0")
+	created, err = CreateDaikinDevice(strings.TrimPrefix(server.URL, "http://"), nil)
+	assert.NoError(t, err)
+	device = created.(LouverController)
+	assert.NoError(t, device.SetLouverSettings(ctx, "cool", LouverSettings{Vertical: LouverSwing, Horizontal: LouverStopped}))
+	assert.Len(t, server.controls(), 2)
+	assert.Equal(t, "0", server.controls()[1]["pow"])
+	assert.Equal(t, "S", server.controls()[1]["f_dir_ud"])
+	assert.Equal(t, "0", server.controls()[1]["f_dir_lr"])
+	assert.NotContains(t, server.controls()[1], "f_dir")
+	assert.ErrorAs(t, device.SetLouverSettings(ctx, "heat", LouverSettings{Vertical: LouverSwing, Horizontal: LouverSwing}), &capabilityErr)
+
+	// A rejected write leaves the settings the unit reported
+	server.set("aircon/set_control_info", "ret=PARAM NG")
+	err = device.SetLouverSettings(ctx, "cool", LouverSettings{Vertical: LouverStopped, Horizontal: LouverSwing})
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "PARAM NG")
+	assert.Len(t, server.controls(), 3)
+	settings, err = device.GetLouverSettings("cool")
+	assert.NoError(t, err)
+	assert.Equal(t, LouverSettings{Vertical: LouverStopped, Horizontal: LouverStopped}, settings)
+	server.set("aircon/set_control_info", "ret=OK")
+
+	var writes []MultiRequest
+	created, err = CreateDaikinDevice(strings.TrimPrefix(newFakeBRP084(t, &writes).URL, "http://"), nil)
+	assert.NoError(t, err)
+	brp084 := created.(LouverController)
+	assert.True(t, brp084.SupportsLouverControl())
+	settings, err = brp084.GetLouverSettings("cool")
+	assert.NoError(t, err)
+	assert.Equal(t, LouverSettings{Vertical: LouverSwing, Horizontal: LouverStopped}, settings)
+
+	assert.NoError(t, brp084.SetLouverSettings(ctx, "cool", LouverSettings{Vertical: LouverFixed(3), Horizontal: LouverComfort}))
+	section := writes[0].Requests[0].PC.Children[0].Children[0]
+	assert.Equal(t, []MultiReqProperty{{Name: "p_05", Value: []byte(`"030000"`)}, {Name: "p_06", Value: []byte(`"140000"`)}}, section.Children)
+	assert.Equal(t, LouverFixed(3), decodeLouverPosition("030000"))
+
+	var skyfi Appliance = NewDaikinSkyFi("127.0.0.1", "pass", nil)
+	_, ok := skyfi.(LouverController)
+	assert.False(t, ok)
+	var airbase Appliance = NewDaikinAirBase("127.0.0.1", nil)
+	_, ok = airbase.(LouverController)
+	assert.False(t, ok)
+}
+
+func TestSchedules(t *testing.T) {
+	server := newFakeBRP069(t, map[string]string{
+		"aircon/get_scdltimer_info": "ret=OK,format=v1,f_detail=total%2318%3b_en%231%3b_pow%231%3b_mode%231%3b_temp%234%3b_time%234%3b_vol%231%3b_dir%231%3b_humi%233%3b_spmd%232," +
+			"scdl_num=3,scdl_per_day=2,en_scdltimer=1,active_no=2,sel_no=2,sche_ver=1,scdl1_name=,scdl2_name=Work%20days,scdl3_name=",
+		"aircon/get_scdltimer_body": "ret=OK,format=v1,target=2,en_scdltimer=1," +
+			"moc=1130240" + "0700A0---00%2f100----2230A0---00,tuc=,wec=------------------,thc=,frc=,sac=,suc=",
+		"aircon/get_timer": "ret=OK,format=v1,f_detail=total%236%3b_en%231%3b_pow%231%3b_time%234,timer_num=2,timer1=110730,timer2=------",
+	})
+
+	ctx := context.Background()
+
+	device, err := CreateDaikinDevice(strings.TrimPrefix(server.URL, "http://"), nil)
+	assert.NoError(t, err)
+	brp069 := device.(*DaikinBRP069)
+
+	info, err := brp069.GetScheduleInfo(ctx)
+	assert.NoError(t, err)
+	assert.True(t, info.Enabled)
+	assert.Equal(t, 2, info.Active)
+	assert.Equal(t, 2, info.PerDay)
+	assert.Equal(t, []string{"", "Work days", ""}, info.Names)
+
+	schedule, err := brp069.GetSchedule(ctx, 2)
+	assert.NoError(t, err)
+	assert.True(t, schedule.Enabled)
+	assert.Len(t, schedule.Days, 1)
+	monday := schedule.Days[time.Monday]
+	assert.Len(t, monday, 2)
+	assert.Equal(t, TimerEntry{
+		Enabled: true, Power: true, Mode: "cool", Temperature: "24.0", Hour: 7, Minute: 0,
+		FanRate: "auto", FanDirection: "off", Humidity: "---", SpecialMode: "00",
+	}, monday[0])
+	assert.False(t, monday[1].Power)
+	assert.Equal(t, "--", monday[1].Temperature)
+
+	schedule.Days[time.Monday] = []TimerEntry{monday[1], monday[0]}
+	schedule.Days[time.Saturday] = []TimerEntry{{Enabled: true, Power: true, Mode: "hot", Temperature: "21.5", Hour: 9, Minute: 15}}
+	assert.NoError(t, brp069.SetSchedule(ctx, schedule))
+	body := server.last("aircon/set_scdltimer_body")
+	assert.Equal(t, "2", body["target"])
+	assert.Equal(t, "11302400700A0---00/100----2230A0---00", body["moc"])
+	assert.Equal(t, "11402150915-------", body["sac"])
+	assert.Equal(t, "", body["tuc"])
+
+	schedule.Days[time.Sunday] = make([]TimerEntry, 3)
+	assert.Error(t, brp069.SetSchedule(ctx, schedule))
+
+	assert.NoError(t, brp069.SetScheduleEnabled(ctx, 2, false))
+	assert.Equal(t, map[string]string{"en_scdltimer": "0", "active_no": "2"}, server.last("aircon/set_scdltimer"))
+
+	timers, err := brp069.GetTimers(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, timers, 2)
+	assert.Equal(t, 7, timers[0].Hour)
+	assert.Equal(t, 30, timers[0].Minute)
+	assert.True(t, timers[0].Power)
+	assert.Equal(t, TimerEntry{}, timers[1])
+
+	assert.NoError(t, brp069.SetTimers(ctx, []TimerEntry{{Enabled: true, Hour: 23}}))
+	assert.Equal(t, "102300", server.last("aircon/set_timer")["timer1"])
+	assert.Equal(t, "------", server.last("aircon/set_timer")["timer2"])
+
+	// Rejected writes are errors
+	server.set("aircon/set_timer", "ret=PARAM NG")
+	server.set("aircon/set_scdltimer", "ret=PARAM NG")
+	server.set("aircon/set_scdltimer_body", "ret=PARAM NG")
+	assert.Error(t, brp069.SetTimers(ctx, nil))
+	assert.Error(t, brp069.SetScheduleEnabled(ctx, 2, true))
+	delete(schedule.Days, time.Sunday)
+	assert.Error(t, brp069.SetSchedule(ctx, schedule))
+}
+
+type fakeClock struct {
+	now time.Time
+	// stop is called once After moves the clock past until
+	until time.Time
+	stop  func()
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.now = c.now.Add(d)
+	if c.stop != nil && c.now.After(c.until) {
+		c.stop()
+	}
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func TestScheduler(t *testing.T) {
+	server := newFakeBRP069(t, nil)
+	ctx := context.Background()
+
+	device, err := CreateDaikinDevice(strings.TrimPrefix(server.URL, "http://"), nil)
+	assert.NoError(t, err)
+
+	berlin := time.FixedZone("CET", 3600)
+	clock := &fakeClock{now: time.Date(2026, 10, 19, 6, 30, 0, 0, time.UTC)} // Monday
+	scheduler := NewScheduler(WithSchedulerClock(clock))
+	scheduler.Register("living", device)
+
+	weekdays := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	assert.NoError(t, scheduler.AddRule(Rule{Name: "morning", Days: weekdays, Hour: 7, Location: time.UTC,
+		Action: SetAction(map[string]string{"pow": "1"})}))
+	assert.NoError(t, scheduler.AddRule(Rule{Name: "night", Hour: 23, Location: berlin,
+		Action: SetAction(map[string]string{"mode": "off"}), Appliances: []string{"living", "bedroom"}}))
+	assert.Error(t, scheduler.AddRule(Rule{Name: "broken", Hour: 24, Action: HolidayAction("on")}))
+
+	next, err := scheduler.NextRun("morning")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC), next)
+	assert.True(t, scheduler.NextRuns()["night"].Equal(time.Date(2026, 10, 19, 22, 0, 0, 0, time.UTC)))
+	_, err = scheduler.NextRun("missing")
+	assert.Error(t, err)
+
+	assert.NoError(t, scheduler.RunPending(ctx))
+	assert.Empty(t, server.controls())
+
+	clock.now = time.Date(2026, 10, 19, 7, 10, 0, 0, time.UTC)
+	assert.NoError(t, scheduler.RunPending(ctx))
+	assert.NoError(t, scheduler.RunPending(ctx))
+	assert.Len(t, server.controls(), 1)
+	assert.Equal(t, "1", server.controls()[0]["pow"])
+
+	// Restarted with the saved run times: the morning run is not repeated
+	restarted := NewScheduler(WithSchedulerClock(clock), WithLastRuns(scheduler.LastRuns()))
+	restarted.Register("living", device)
+	assert.NoError(t, restarted.AddRule(Rule{Name: "morning", Days: weekdays, Hour: 7, Location: time.UTC,
+		Action: SetAction(map[string]string{"pow": "1"})}))
+	assert.NoError(t, restarted.RunPending(ctx))
+	assert.Len(t, server.controls(), 1)
+
+	// Down overnight: the morning run is caught up, last night's is too old
+	clock.now = time.Date(2026, 10, 20, 7, 30, 0, 0, time.UTC)
+	assert.NoError(t, scheduler.RunPending(ctx))
+	assert.Len(t, server.controls(), 2)
+
+	clock.now = time.Date(2026, 10, 23, 8, 0, 0, 0, time.UTC) // Friday
+	next, _ = scheduler.NextRun("morning")
+	assert.Equal(t, time.Date(2026, 10, 26, 7, 0, 0, 0, time.UTC), next)
+
+	clock.now = time.Date(2026, 10, 23, 21, 55, 0, 0, time.UTC)
+	runCtx, cancel := context.WithCancel(ctx)
+	clock.until, clock.stop = time.Date(2026, 10, 23, 22, 30, 0, 0, time.UTC), cancel
+	scheduler.RemoveRule("morning")
+	assert.ErrorIs(t, scheduler.Run(runCtx), context.Canceled)
+	assert.Len(t, server.controls(), 3)
+	assert.Equal(t, "0", server.controls()[2]["pow"])
+}
+
+func TestDemandControl(t *testing.T) {
+	server := newFakeBRP069(t, map[string]string{
+		"aircon/get_model_info":     fakeBRP069Resources["aircon/get_model_info"] + ",dmnd=1",
+		"aircon/get_demand_control": "ret=OK,type=1,en_demand=1,mode=0,max_pow=70,scdl_per_day=4,moc=0,tuc=1070080%2f0180000,wec=0,thc=0,frc=0,sac=0,suc=0",
+	})
+
+	ctx := context.Background()
+
+	device, err := CreateDaikinDevice(strings.TrimPrefix(server.URL, "http://"), nil)
+	assert.NoError(t, err)
+	brp069 := device.(*DaikinBRP069)
+	assert.True(t, brp069.SupportsDemandControl())
+	assert.Equal(t, "3", brp069.Values.All()["mode"])
+
+	control, err := brp069.GetDemandControl(ctx)
+	assert.NoError(t, err)
+	assert.True(t, control.Enabled)
+	assert.Equal(t, DemandManual, control.Mode)
+	assert.Equal(t, 70, control.MaxPower)
+	assert.Empty(t, control.Schedule[time.Sunday])
+	assert.Equal(t, []string{"1070080", "0180000"}, control.Schedule[time.Tuesday])
+
+	control.MaxPower = 30
+	assert.Error(t, brp069.SetDemandControl(ctx, *control))
+	control.Mode = "eco"
+	control.MaxPower = 50
+	assert.Error(t, brp069.SetDemandControl(ctx, *control))
+	assert.Empty(t, server.requests("aircon/set_demand_control"))
+
+	control.Mode = DemandScheduled
+	assert.NoError(t, brp069.SetDemandControl(ctx, *control))
+	params := server.last("aircon/set_demand_control")
+	assert.Equal(t, "1", params["en_demand"])
+	assert.Equal(t, "1", params["mode"])
+	assert.Equal(t, "50", params["max_pow"])
+	assert.Equal(t, "0", params["moc"])
+	assert.Equal(t, "1070080/0180000", params["tuc"])
+
+	control.Schedule[time.Monday] = []string{"1,mode=0"}
+	assert.Error(t, brp069.SetDemandControl(ctx, *control))
+	delete(control.Schedule, time.Monday)
+
+	server.set("aircon/set_demand_control", "ret=PARAM NG")
+	assert.Error(t, brp069.SetDemandControl(ctx, *control))
+
+	plain, err := CreateDaikinDevice(strings.TrimPrefix(newFakeBRP069(t, nil).URL, "http://"), nil)
+	assert.NoError(t, err)
+	assert.False(t, plain.(*DaikinBRP069).SupportsDemandControl())
+	_, err = plain.(*DaikinBRP069).GetDemandControl(ctx)
+	var capabilityErr *CapabilityError
+	assert.ErrorAs(t, err, &capabilityErr)
+}
+
+func TestEnergyCost(t *testing.T) {
+	hours := func(values map[int]int) string {
+		list := make([]string, 24)
+		for hour := range list {
+			list[hour] = fmt.Sprint(values[hour])
+		}
+		return strings.Join(list, "/")
+	}
+
+	server := newFakeBRP069(t, map[string]string{
+		"aircon/get_price":        "ret=OK,price_int=0,price_dec=27",
+		"aircon/get_day_power_ex": "ret=OK,curr_day_cool=" + hours(map[int]int{8: 5, 13: 10, 20: 3}) + ",curr_day_heat=" + hours(map[int]int{8: 5}),
+		"aircon/get_week_power":   "ret=OK,today_runtime=90,datas=1000/2000/0/0/0/0/3000",
+		"aircon/get_year_power":   "ret=OK,previous_year=10/20/0/0/0/0/0/0/0/0/0/40,this_year=123/0/0/0/0/0/0/0/0/0/0/0",
+	})
+
+	ctx := context.Background()
+
+	device, err := CreateDaikinDevice(strings.TrimPrefix(server.URL, "http://"), nil)
+	assert.NoError(t, err)
+	brp069 := device.(*DaikinBRP069)
+
+	price, err := brp069.GetPrice()
+	assert.NoError(t, err)
+	assert.Equal(t, 0.27, price)
+
+	now := time.Date(2024, 1, 15, 13, 5, 0, 0, time.UTC)
+	history, err := brp069.GetEnergyHistory(now)
+	assert.NoError(t, err)
+	assert.Len(t, history.Hourly, 14)
+	assert.InDelta(t, 1.0, history.Hourly[8].Energy, 1e-9)
+	assert.Len(t, history.Daily, 7)
+	assert.Equal(t, time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC), history.Daily[0].Start)
+	assert.Len(t, history.Monthly, 13)
+	assert.InDelta(t, 12.3, history.Monthly[12].Energy, 1e-9)
+
+	tariff := TimeOfUseTariff{Default: 0.20, Periods: []TariffPeriod{{Start: 7, End: 9, Rate: 0.40}}}
+	report := CalculateCost(history, tariff)
+	assert.Len(t, report.Daily, 7)
+	today := report.Daily[6]
+	assert.InDelta(t, 2.0, today.Energy, 1e-9)
+	assert.InDelta(t, 0.6, today.Cost, 1e-9)
+	assert.InDelta(t, (2*0.4+22*0.2)/24, report.Daily[0].Cost, 1e-9)
+	assert.Len(t, report.Monthly, 13)
+	assert.Equal(t, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), report.Monthly[0].Start)
+
+	night := TariffPeriod{Days: []time.Weekday{time.Friday}, Start: 22, End: 6, Rate: 0.1}
+	assert.True(t, night.covers(time.Date(2024, 1, 13, 5, 0, 0, 0, time.UTC))) // Saturday morning
+	assert.False(t, night.covers(time.Date(2024, 1, 13, 23, 0, 0, 0, time.UTC)))
+
+	var writes []MultiRequest
+	brp084, err := CreateDaikinDevice(strings.TrimPrefix(newFakeBRP084(t, &writes).URL, "http://"), nil)
+	assert.NoError(t, err)
+
+	appliances := map[string]Appliance{"living": device, "office": brp084}
+	reports, err := CostReports(appliances, FlatRate(0.5), now)
+	assert.NoError(t, err)
+	assert.Equal(t, "office", reports["office"].Appliance)
+	assert.Len(t, reports["office"].Daily, 3)
+	assert.InDelta(t, 0.003, reports["office"].DailyTotal(), 1e-9)
+	assert.Len(t, reports["office"].Monthly, 1)
+
+	_, err = CostReports(appliances, nil, now)
+	var capabilityErr *CapabilityError
+	assert.ErrorAs(t, err, &capabilityErr)
+
+	reports, err = CostReports(map[string]Appliance{"living": device}, nil, now)
+	assert.NoError(t, err)
+	assert.InDelta(t, 2.0*0.27, reports["living"].Daily[6].Cost, 1e-9)
+
+	assert.NoError(t, brp069.SetPrice(ctx, 0.3))
+	assert.Equal(t, map[string]string{"price_int": "0", "price_dec": "30"}, server.last("aircon/set_price"))
+	price, _ = brp069.GetPrice()
+	assert.Equal(t, 0.3, price)
+
+	// price_dec is hundredths, not the digits after the point
+	brp069.Values.Update(map[string]string{"price_int": "1", "price_dec": "5"})
+	price, err = brp069.GetPrice()
+	assert.NoError(t, err)
+	assert.Equal(t, 1.05, price)
+	brp069.Values.Set("price_dec", "150")
+	_, err = brp069.GetPrice()
+	assert.Error(t, err)
+
+	server.set("aircon/set_price", "ret=PARAM NG")
+	assert.Error(t, brp069.SetPrice(ctx, 0.4))
+	assert.Equal(t, "150", brp069.Values.All()["price_dec"])
+}
+
+func TestDeviceInfo(t *testing.T) {
+	device, err := CreateDaikinDevice(strings.TrimPrefix(newFakeBRP069(t, nil).URL, "http://"), nil)
+	assert.NoError(t, err)
+
+	info := device.(InfoReporter).Info()
+	assert.Equal(t, "BRP069", info.DeviceType)
+	assert.Equal(t, "Living", info.Name)
+	assert.Equal(t, "AA:BB:CC:DD:EE:FF", info.MAC)
+	assert.Equal(t, "1_2_54", info.FirmwareString)
+	assert.Equal(t, Version{Major: 1, Minor: 2, Patch: 54}, info.Firmware)
+	assert.Equal(t, "eu", info.Region)
+	assert.Equal(t, "2", info.ProtocolVersion)
+	device.GetValues().Set("ssid", "%48%6f%6d%65%20%4e%65%74")
+	assert.Equal(t, "Home Net", device.(InfoReporter).Info().SSID)
+	assert.True(t, info.Firmware.AtLeast(Version{Major: 1, Minor: 2}))
+	assert.False(t, info.Firmware.AtLeast(Version{Major: 1, Minor: 14}))
+
+	var writes []MultiRequest
+	brp084, err := CreateDaikinDevice(strings.TrimPrefix(newFakeBRP084(t, &writes).URL, "http://"), nil)
+	assert.NoError(t, err)
+	info = brp084.(InfoReporter).Info()
+	assert.Equal(t, "BRP084", info.DeviceType)
+	assert.Equal(t, "Office", info.Name)
+	assert.Equal(t, "Daikin+AP 100%", info.SSID)
+	assert.Equal(t, "2.8.0", info.Firmware.String())
+	assert.Equal(t, 1, info.Firmware.Compare(Version{Major: 1, Minor: 14, Patch: 88}))
+
+	_, err = ParseVersion("v1.2")
+	assert.Error(t, err)
+	version, err := ParseVersion("3_3")
+	assert.NoError(t, err)
+	assert.Equal(t, "3.3.0", version.String())
+}
+
+func TestRename(t *testing.T) {
+	resources := map[string]string{
+		"skyfi/aircon/get_zone_setting": "ret=OK,zone_name=%4c%69%76%69%6e%67%3bBed%201%3bStudy,zone_onoff=1%3b0%3b1,lztemp_c=24%3b24%3b25,lztemp_h=20%3b20%3b21",
+	}
+	queries := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/")
+		if body, exists := resources[path]; exists {
+			fmt.Fprint(w, body)
+			return
+		}
+		queries[path] = r.URL.RawQuery
+		fmt.Fprint(w, "ret=OK")
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	brp069 := NewDaikinBRP069(strings.TrimPrefix(server.URL, "http://"), nil)
+	brp069.BaseURL = server.URL
+	assert.NoError(t, brp069.SetName(ctx, "Wohnzimmer ü"))
+	assert.Equal(t, "name=%57%6f%68%6e%7a%69%6d%6d%65%72%20%c3%bc", queries["common/set_name"])
+	assert.Equal(t, "Wohnzimmer ü", brp069.Info().Name)
+	assert.Error(t, brp069.SetName(ctx, ""))
+
+	airbase := NewDaikinAirBase(strings.TrimPrefix(server.URL, "http://"), nil)
+	airbase.BaseURL = server.URL
+	var renamer Renamer = airbase
+	assert.NoError(t, renamer.SetName(ctx, "Home"))
+	assert.Equal(t, "name=%48%6f%6d%65", queries["skyfi/common/set_name"])
+
+	assert.NoError(t, airbase.SetZoneName(ctx, 1, "Bed 2"))
+	query, err := url.ParseQuery(queries["skyfi/aircon/set_zone_setting"])
+	assert.NoError(t, err)
+	assert.Equal(t, "Living;Bed 2;Study", query.Get("zone_name"))
+	assert.Equal(t, "1;0;1", query.Get("zone_onoff"))
+	assert.Equal(t, "20;20;21", query.Get("lztemp_h"))
+	_, names := airbase.Represent("zone_name")
+	assert.Equal(t, []string{"Living", "Bed 2", "Study"}, names)
+	var zones ZoneSetting
+	assert.NoError(t, airbase.DecodeValues(&zones))
+	assert.Equal(t, []string{"Living", "Bed 2", "Study"}, zones.Names)
+	assert.Equal(t, []bool{true, false, true}, zones.OnOff)
+
+	assert.Error(t, airbase.SetZoneName(ctx, 3, "Attic"))
+	assert.Error(t, airbase.SetZoneName(ctx, 0, "a;b"))
+
+	resources["common/set_name"] = "ret=PARAM NG"
+	resources["skyfi/aircon/set_zone_setting"] = "ret=PARAM NG"
+	assert.Error(t, brp069.SetName(ctx, "Kitchen"))
+	assert.Equal(t, "Wohnzimmer ü", brp069.Info().Name)
+	assert.Error(t, airbase.SetZoneName(ctx, 0, "Lounge"))
+	_, names = airbase.Represent("zone_name")
+	assert.Equal(t, []string{"Living", "Bed 2", "Study"}, names)
+}
+
+func TestClock(t *testing.T) {
+	var requests []string
+	var ahead time.Duration
+	reject := false
+	cet := time.FixedZone("CET", 3600)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/")
+		requests = append(requests, path+"?"+r.URL.RawQuery)
+		if path == "common/get_datetime" && ahead != 0 {
+			fmt.Fprintf(w, "ret=OK,sta=2,cur=%s", time.Now().Add(ahead).In(cet).Format("2006/1/2 15:04:05"))
+			return
+		}
+		if path == "common/notify_date_time" && reject {
+			fmt.Fprint(w, "ret=PARAM NG")
+			return
+		}
+		if body, exists := fakeBRP069Resources[path]; exists {
+			fmt.Fprint(w, body)
+			return
+		}
+		fmt.Fprint(w, "ret=OK")
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	deviceID := strings.TrimPrefix(server.URL, "http://")
+	device, err := CreateDaikinDevice(deviceID, nil, WithClockLocation(cet), WithoutClockSync())
+	assert.NoError(t, err)
+	assert.NotContains(t, requests, "common/get_datetime?cur=")
+
+	clock, ok := device.(Clock)
+	assert.True(t, ok)
+	deviceTime, err := clock.GetClock(ctx)
+	assert.NoError(t, err)
+	assert.True(t, deviceTime.Equal(time.Date(2024, 1, 15, 12, 5, 12, 0, time.UTC)))
+
+	ahead = 90 * time.Second
+	drift, err := ClockDrift(ctx, clock)
+	assert.NoError(t, err)
+	assert.InDelta(t, 90, drift.Seconds(), 2)
+	ahead = 0
+
+	at := time.Date(2024, 7, 1, 22, 30, 0, 0, time.UTC)
+	assert.NoError(t, clock.SetClock(ctx, at, time.FixedZone("Europe/Berlin", 7200)))
+	assert.Equal(t, "common/notify_date_time?date=2024%2F07%2F02&time=00%3A30%3A00&zone=Europe%2FBerlin", requests[len(requests)-1])
+	assert.NoError(t, clock.SetClock(ctx, at, time.UTC))
+	assert.Equal(t, "common/notify_date_time?date=2024%2F07%2F01&time=22%3A30%3A00&zone=GMT", requests[len(requests)-1])
+	assert.NoError(t, clock.SetClock(ctx, at, nil))
+	assert.Equal(t, "common/notify_date_time?date=2024%2F07%2F01&time=23%3A30%3A00&zone=CET", requests[len(requests)-1])
+	assert.Equal(t, cet, device.(*DaikinBRP069).ClockLocation)
+
+	reject = true
+	assert.Error(t, clock.SetClock(ctx, at, nil))
+	reject = false
+
+	// With a location configured, Init sets the clock in it
+	requests = nil
+	_, err = CreateDaikinDevice(deviceID, nil, WithClockLocation(cet))
+	assert.NoError(t, err)
+	assert.NotContains(t, requests, "common/get_datetime?cur=")
+	assert.Contains(t, strings.Join(requests, "\n"), "common/notify_date_time?date=")
+	assert.Contains(t, strings.Join(requests, "\n"), "zone=CET")
+
+	requests = nil
+	utc, err := CreateDaikinDevice(deviceID, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, requests, "common/get_datetime?cur=")
+	deviceTime, err = utc.(Clock).GetClock(ctx)
+	assert.NoError(t, err)
+	assert.True(t, deviceTime.Equal(time.Date(2024, 1, 15, 13, 5, 12, 0, time.UTC)))
+
+	var skyfi Appliance = NewDaikinSkyFi("127.0.0.1", "pass", nil)
+	_, ok = skyfi.(Clock)
+	assert.False(t, ok)
+	var writes []MultiRequest
+	brp084, err := CreateDaikinDevice(strings.TrimPrefix(newFakeBRP084(t, &writes).URL, "http://"), nil)
+	assert.NoError(t, err)
+	_, ok = brp084.(Clock)
+	assert.False(t, ok)
+}
+
+func TestRemoteSettings(t *testing.T) {
+	server := newFakeBRP069(t, map[string]string{
+		"common/get_remote_method": "ret=OK,method=home%20only,notice_ip_int=3600,notice_sync_int=60",
+	})
+
+	ctx := context.Background()
+
+	device, err := CreateDaikinDevice(strings.TrimPrefix(server.URL, "http://"), nil)
+	assert.NoError(t, err)
+	controller, ok := device.(RemoteController)
+	assert.True(t, ok)
+	assert.True(t, controller.SupportsRemoteSettings())
+
+	settings, err := controller.GetRemoteSettings(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, RemoteSettings{Method: RemoteHomeOnly, NoticeInterval: time.Hour, SyncInterval: time.Minute}, *settings)
+
+	assert.NoError(t, controller.SetRemoteSettings(ctx, RemoteSettings{Method: RemotePolling, SyncInterval: 30 * time.Second}))
+	assert.Equal(t, map[string]string{"method": "polling", "notice_ip_int": "3600", "notice_sync_int": "30"}, server.last("common/set_remote_method"))
+	assert.Error(t, controller.SetRemoteSettings(ctx, RemoteSettings{Method: "cloud"}))
+
+	server.set("common/set_remote_method", "ret=PARAM NG")
+	assert.Error(t, controller.SetRemoteSettings(ctx, RemoteSettings{Method: RemoteHomeOnly}))
+	assert.Equal(t, "polling", device.GetValues().All()["method"])
+
+	var writes []MultiRequest
+	brp084, err := CreateDaikinDevice(strings.TrimPrefix(newFakeBRP084(t, &writes).URL, "http://"), nil)
+	assert.NoError(t, err)
+	assert.False(t, brp084.GetValues().Has("cloud"))
+	controller = brp084.(RemoteController)
+	assert.True(t, controller.SupportsRemoteSettings())
+	assert.NoError(t, controller.SetRemoteSettings(ctx, RemoteSettings{Method: RemoteHomeOnly}))
+	assert.Equal(t, "/dsiot/edge.adp_d", writes[0].Requests[0].To)
+	assert.Equal(t, []MultiReqProperty{{Name: "cloud", Value: []byte(`"00"`)}}, writes[0].Requests[0].PC.Children)
+	settings, err = controller.GetRemoteSettings(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, RemotePolling, settings.Method)
+
+	var skyfi Appliance = NewDaikinSkyFi("127.0.0.1", "pass", nil)
+	_, ok = skyfi.(RemoteController)
+	assert.False(t, ok)
+}
+
+// newFakeAccessPoint serves an adapter in access-point mode that stores the
+// network it is given, or rejects it if reject is set
+func newFakeAccessPoint(t *testing.T, reject bool, rebooted *bool) *httptest.Server {
+	t.Helper()
+	settings := map[string]string{"ssid": "", "security": "none", "key": ""}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch strings.TrimPrefix(r.URL.Path, "/") {
+		case "common/get_wifi_setting":
+			fmt.Fprintf(w, "ret=OK,ssid=%s,security=%s,key=%s,link=0",
+				encodeValue(settings["ssid"]), settings["security"], encodeValue(settings["key"]))
+		case "common/set_wifi_setting":
+			if reject {
+				fmt.Fprint(w, "ret=PARAM NG")
+				return
+			}
+			for key := range settings {
+				settings[key] = r.URL.Query().Get(key)
+			}
+			fmt.Fprint(w, "ret=OK")
+		case "common/reboot":
+			*rebooted = true
+			fmt.Fprint(w, "ret=OK")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestProvision(t *testing.T) {
+	ctx := context.Background()
+	var rebooted bool
+	server := newFakeAccessPoint(t, false, &rebooted)
+	apAddr := strings.TrimPrefix(server.URL, "http://")
+
+	for _, invalid := range []struct {
+		ssid       string
+		security   WifiSecurity
+		passphrase string
+	}{
+		{"", WifiMixed, "correct horse"},
+		{strings.Repeat("x", 33), WifiMixed, "correct horse"},
+		{"Home", WifiWPA2, "short"},
+		{"Home", WifiWEP, "zzzzzzzzzz"},
+		{"Home", WifiOpen, "secret"},
+		{"Home", "wpa3", "correct horse"},
+	} {
+		assert.Error(t, Provision(ctx, apAddr, invalid.ssid, invalid.security, invalid.passphrase))
+	}
+	assert.False(t, rebooted)
+
+	assert.NoError(t, Provision(ctx, apAddr, "Home Net ü", WifiMixed, "correct horse"))
+	assert.True(t, rebooted)
+
+	device := NewDaikinBRP069(apAddr, nil)
+	settings, err := device.GetWifiSettings(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, WifiSettings{SSID: "Home Net ü", Security: WifiMixed}, *settings)
+
+	rebooted = false
+	rejecting := newFakeAccessPoint(t, true, &rebooted)
+	err = Provision(ctx, strings.TrimPrefix(rejecting.URL, "http://"), "Home", WifiWPA2, "correct horse")
+	assert.ErrorContains(t, err, "ret=PARAM NG")
+	assert.False(t, rebooted)
+
+	assert.Error(t, Provision(ctx, strings.TrimPrefix(newFakeBRP069(t, nil).URL, "http://"), "Home", WifiWPA2, "correct horse"))
+
+	// A BRP072C key can come from a credential provider
+	var registered []string
+	accessPoint := newFakeAccessPoint(t, false, &rebooted).Config.Handler
+	brp072c := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/common/register_terminal" {
+			registered = append(registered, r.URL.Query().Get("key"))
+			fmt.Fprint(w, "ret=OK")
+			return
+		}
+		accessPoint.ServeHTTP(w, r)
+	}))
+	defer brp072c.Close()
+	provider := CredentialsFunc(func(context.Context) (Credentials, error) {
+		return Credentials{Key: "provided-key"}, nil
+	})
+	rebooted = false
+	assert.NoError(t, Provision(ctx, strings.TrimPrefix(brp072c.URL, "https://"), "Home", WifiWPA2, "correct horse", WithCredentialProvider(provider)))
+	assert.Equal(t, []string{"provided-key"}, registered)
+	assert.True(t, rebooted)
+}
+
+func TestMaintenance(t *testing.T) {
+	server := newFakeBRP069(t, map[string]string{
+		"common/basic_info": fakeBRP069Resources["common/basic_info"] + ",led=1",
+		"common/get_notify": "ret=OK,auto_off_flg=0,auto_off_tm=-",
+		"common/reboot":     "ret=OK",
+	})
+
+	ctx := context.Background()
+
+	device, err := CreateDaikinDevice(strings.TrimPrefix(server.URL, "http://"), nil)
+	assert.NoError(t, err)
+	brp069 := device.(*DaikinBRP069)
+
+	assert.True(t, brp069.SupportsLED())
+	on, err := brp069.GetLED()
+	assert.NoError(t, err)
+	assert.True(t, on)
+	assert.NoError(t, brp069.SetLED(ctx, false))
+	assert.Equal(t, map[string]string{"led": "0"}, server.last("common/set_led"))
+	on, _ = brp069.GetLED()
+	assert.False(t, on)
+
+	notify, err := brp069.GetNotifySettings()
+	assert.NoError(t, err)
+	assert.Equal(t, NotifySettings{}, notify)
+	assert.Error(t, brp069.SetNotifySettings(ctx, NotifySettings{AutoOff: true}))
+	assert.Error(t, brp069.SetNotifySettings(ctx, NotifySettings{AutoOff: true, AutoOffDelay: 90 * time.Second}))
+	assert.NoError(t, brp069.SetNotifySettings(ctx, NotifySettings{AutoOff: true, AutoOffDelay: 2 * time.Hour}))
+	assert.Equal(t, map[string]string{"auto_off_flg": "1", "auto_off_tm": "120"}, server.last("common/set_notify"))
+	notify, _ = brp069.GetNotifySettings()
+	assert.Equal(t, NotifySettings{AutoOff: true, AutoOffDelay: 2 * time.Hour}, notify)
+
+	assert.NoError(t, brp069.Reboot(ctx))
+
+	// An adapter that answers twice more before going down for three
+	// requests
+	var attempts int
+	rebooting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts > 2 && attempts <= 5 {
+			http.Error(w, "rebooting", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, fakeBRP069Resources["common/basic_info"])
+	}))
+	defer rebooting.Close()
+
+	restarted := NewDaikinBRP069(strings.TrimPrefix(rebooting.URL, "http://"), nil)
+	assert.NoError(t, restarted.WaitOnline(ctx, time.Millisecond))
+	assert.Equal(t, 6, attempts)
+
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	offline := NewDaikinBRP069("127.0.0.1:1", nil)
+	var connectionErr *ConnectionError
+	assert.ErrorAs(t, offline.WaitOnline(timeout, time.Millisecond), &connectionErr)
+	assert.Contains(t, connectionErr.Error(), "come back")
+
+	timeout, cancel = context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorAs(t, brp069.WaitOnline(timeout, time.Millisecond), &connectionErr)
+	assert.Contains(t, connectionErr.Error(), "go down")
+}
+
+// fakeMeterProvider keeps the callbacks registered on its meter
+type fakeMeterProvider struct {
+	noop.MeterProvider
+	meter *fakeMeter
+}
+
+func (p *fakeMeterProvider) Meter(string, ...metric.MeterOption) metric.Meter { return p.meter }
+
+type fakeMeter struct {
+	noop.Meter
+	callbacks    []metric.Callback
+	unregistered int
+}
+
+func (m *fakeMeter) RegisterCallback(callback metric.Callback, _ ...metric.Observable) (metric.Registration, error) {
+	m.callbacks = append(m.callbacks, callback)
+	return &fakeRegistration{meter: m}, nil
+}
+
+// fakeRegistration counts the callbacks unregistered from its meter
+type fakeRegistration struct {
+	embedded.Registration
+	meter *fakeMeter
+}
+
+func (r *fakeRegistration) Unregister() error {
+	r.meter.unregistered++
+	return nil
+}
+
+// fakeObserver keeps observations by their attributes
+type fakeObserver struct {
+	embedded.Observer
+	observed map[string][]float64
+}
+
+func (o *fakeObserver) observe(value float64, opts []metric.ObserveOption) {
+	attributes := metric.NewObserveConfig(opts).Attributes()
+	quantile, _ := attributes.Value(attrQuantile)
+	o.observed[quantile.AsString()] = append(o.observed[quantile.AsString()], value)
+}
+
+func (o *fakeObserver) ObserveFloat64(_ metric.Float64Observable, value float64, opts ...metric.ObserveOption) {
+	o.observe(value, opts)
+}
+
+func (o *fakeObserver) ObserveInt64(_ metric.Int64Observable, value int64, opts ...metric.ObserveOption) {
+	o.observe(float64(value), opts)
+}
+
+func TestAdapterHealth(t *testing.T) {
+	tracker := newHealthTracker()
+	assert.Equal(t, 0, tracker.snapshot().Requests)
+	for i := 1; i <= 120; i++ {
+		var err error
+		if i%10 == 0 {
+			err = fmt.Errorf("timeout")
+		}
+		tracker.record(time.Duration(i)*time.Millisecond, err)
+	}
+	health := tracker.snapshot()
+	assert.Equal(t, healthWindow, health.Requests)
+	assert.Equal(t, 10, health.Errors)
+	assert.Equal(t, 0.1, health.ErrorRate)
+	assert.Equal(t, 70*time.Millisecond, health.LatencyP50)
+	assert.Equal(t, 115*time.Millisecond, health.LatencyP95)
+	assert.Equal(t, 119*time.Millisecond, health.LatencyP99)
+
+	adapter := fakeBRP069Adapter(map[string]string{
+		"common/get_wifi_setting": "ret=OK,ssid=Home,security=mixed,key=,link=1,rssi=-58",
+	})
+	adapter.missing = http.StatusServiceUnavailable
+	server := httptest.NewServer(adapter)
+	defer server.Close()
+	ctx := context.Background()
+
+	meterProvider := &fakeMeterProvider{meter: &fakeMeter{}}
+	device, err := CreateDaikinDevice(strings.TrimPrefix(server.URL, "http://"), nil, WithMetrics(meterProvider))
+	assert.NoError(t, err)
+	assert.Len(t, meterProvider.meter.callbacks, 1)
+
+	requests := device.(*DaikinBRP069).health.snapshot().Requests
+	report, err := device.(HealthReporter).Health(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, -58, *report.RSSI)
+	assert.Equal(t, requests, report.Requests)
+	assert.Greater(t, report.Requests, 5)
+	assert.Greater(t, report.Errors, 0)
+	assert.False(t, report.LastSuccess.IsZero())
+	assert.False(t, report.LastError.IsZero())
+
+	observer := &fakeObserver{observed: make(map[string][]float64)}
+	assert.NoError(t, meterProvider.meter.callbacks[0](ctx, observer))
+	// The gauges also cover the signal read
+	assert.Contains(t, observer.observed[""], float64(report.Requests+1))
+	assert.Contains(t, observer.observed[""], -58.0)
+	assert.Len(t, observer.observed["p95"], 1)
+
+	// A failed read clears the signal
+	adapter.set("common/get_wifi_setting", "ret=PARAM NG")
+	report, err = device.(HealthReporter).Health(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, report.RSSI)
+	observer = &fakeObserver{observed: make(map[string][]float64)}
+	assert.NoError(t, meterProvider.meter.callbacks[0](ctx, observer))
+	assert.NotContains(t, observer.observed[""], -58.0)
+
+	assert.NoError(t, device.(io.Closer).Close())
+	assert.Equal(t, 1, meterProvider.meter.unregistered)
+
+	var writes []MultiRequest
+	meterProvider = &fakeMeterProvider{meter: &fakeMeter{}}
+	brp084, err := CreateDaikinDevice(strings.TrimPrefix(newFakeBRP084(t, &writes).URL, "http://"), nil, WithMetrics(meterProvider))
+	assert.NoError(t, err)
+	report, err = brp084.(HealthReporter).Health(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, -61, *report.RSSI)
+	assert.Equal(t, time.Hour, report.Uptime)
+	assert.Equal(t, 0, report.Errors)
+
+	// Closing a unit leaves the adapter's gauges registered
+	unit := brp084.(*DaikinBRP084).ForUnit(BRP084Unit{Address: "adr_0101"})
+	assert.NoError(t, unit.Close())
+	assert.Equal(t, 0, meterProvider.meter.unregistered)
+	observer = &fakeObserver{observed: make(map[string][]float64)}
+	assert.NoError(t, meterProvider.meter.callbacks[0](ctx, observer))
+	assert.Contains(t, observer.observed[""], -61.0)
+
+	assert.NoError(t, brp084.(io.Closer).Close())
+	assert.Equal(t, 1, meterProvider.meter.unregistered)
+}
+
+func TestFaults(t *testing.T) {
+	assert.Equal(t, "Communication error between indoor and outdoor units", DescribeFault("u4"))
+	assert.Equal(t, "Unknown fault", DescribeFault("Z9"))
+	assert.Equal(t, "U4", decodeFaultCode("5534"))
+	assert.Equal(t, "0", decodeFaultCode("0000"))
+	assert.Equal(t, "FFFF", decodeFaultCode("FFFF"))
+
+	server := newFakeBRP069(t, nil)
+	setError := func(code string) {
+		server.set("aircon/get_sensor_info", strings.Replace(fakeBRP069Resources["aircon/get_sensor_info"], "err=0", "err="+code, 1))
+	}
+
+	device, err := CreateDaikinDevice(strings.TrimPrefix(server.URL, "http://"), nil)
+	assert.NoError(t, err)
+	faults, err := device.(FaultReporter).Faults()
+	assert.NoError(t, err)
+	assert.Empty(t, faults)
+
+	setError("37")
+	assert.NoError(t, device.UpdateStatus(context.Background()))
+	faults, _ = device.(FaultReporter).Faults()
+	assert.Equal(t, []Fault{{Code: "37", Description: "Unknown fault"}}, faults)
+	setError("0")
+	assert.NoError(t, device.UpdateStatus(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := WatchFaults(ctx, device, time.Millisecond)
+	setError("U4")
+	event := <-events
+	assert.NoError(t, event.Err)
+	assert.False(t, event.Cleared)
+	assert.Equal(t, Fault{Code: "U4", Description: FaultDescriptions["U4"]}, event.Fault)
+
+	setError("0")
+	event = <-events
+	assert.True(t, event.Cleared)
+	assert.Equal(t, "U4", event.Fault.Code)
+
+	cancel()
+	for range events {
+	}
+
+	var writes []MultiRequest
+	brp084Server := newFakeBRP084(t, &writes)
+	brp084, err := CreateDaikinDevice(strings.TrimPrefix(brp084Server.URL, "http://"), nil)
+	assert.NoError(t, err)
+	faults, err = brp084.(FaultReporter).Faults()
+	assert.NoError(t, err)
+	assert.Equal(t, []Fault{{Code: "A5", Description: FaultDescriptions["A5"], Warning: true}}, faults)
+
+	// A unit that stops answering
+	brp084Server.Close()
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	event = <-WatchFaults(ctx, brp084, time.Millisecond)
+	assert.Error(t, event.Err)
+
+	// A unit without fault codes gets one event and a closed channel
+	var skyfi Appliance = NewDaikinSkyFi("127.0.0.1", "secret", nil)
+	_, ok := skyfi.(FaultReporter)
+	assert.False(t, ok)
+	skyfiEvents := WatchFaults(ctx, skyfi, time.Millisecond)
+	event = <-skyfiEvents
+	var capabilityErr *CapabilityError
+	assert.ErrorAs(t, event.Err, &capabilityErr)
+	_, open := <-skyfiEvents
+	assert.False(t, open)
+
+	brp084.GetValues().Delete("err")
+	brp084.GetValues().Delete("warn")
+	assert.False(t, brp084.(FaultReporter).SupportsFaults())
+}
@@ -0,0 +1,189 @@
+package godaikin
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+)
+
+// HealthReporter is implemented by appliances reporting how well their
+// adapter is reachable
+type HealthReporter interface {
+	Health(ctx context.Context) (*AdapterHealth, error)
+}
+
+// healthWindow is how many recent requests AdapterHealth covers
+const healthWindow = 100
+
+// AdapterHealth reports how well an adapter is reachable: the Wi-Fi signal
+// it reports and the latency and failures of the library's last requests
+type AdapterHealth struct {
+	// RSSI is the Wi-Fi signal in dBm, nil if the adapter does not report it
+	RSSI *int
+	// Uptime is how long the adapter has been running, zero if unknown
+	Uptime time.Duration
+
+	// Requests is the number of recent requests the figures below cover
+	Requests   int
+	Errors     int
+	ErrorRate  float64
+	LatencyP50 time.Duration
+	LatencyP95 time.Duration
+	LatencyP99 time.Duration
+
+	LastSuccess time.Time
+	LastError   time.Time
+}
+
+type healthSample struct {
+	latency time.Duration
+	failed  bool
+}
+
+// healthTracker keeps the outcome of an adapter's recent requests. Units
+// behind one BRP084 adapter share it.
+type healthTracker struct {
+	mu          sync.Mutex
+	samples     []healthSample
+	next        int
+	lastSuccess time.Time
+	lastError   time.Time
+	rssi        *int
+}
+
+func newHealthTracker() *healthTracker {
+	return &healthTracker{samples: make([]healthSample, 0, healthWindow)}
+}
+
+func (h *healthTracker) record(latency time.Duration, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sample := healthSample{latency: latency, failed: err != nil}
+	if len(h.samples) < healthWindow {
+		h.samples = append(h.samples, sample)
+	} else {
+		h.samples[h.next] = sample
+	}
+	h.next = (h.next + 1) % healthWindow
+
+	if err != nil {
+		h.lastError = time.Now()
+	} else {
+		h.lastSuccess = time.Now()
+	}
+}
+
+func (h *healthTracker) setRSSI(rssi *int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.rssi = rssi
+}
+
+func (h *healthTracker) snapshot() AdapterHealth {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	health := AdapterHealth{
+		RSSI:        h.rssi,
+		Requests:    len(h.samples),
+		LastSuccess: h.lastSuccess,
+		LastError:   h.lastError,
+	}
+	if len(h.samples) == 0 {
+		return health
+	}
+
+	latencies := make([]time.Duration, len(h.samples))
+	for i, sample := range h.samples {
+		latencies[i] = sample.latency
+		if sample.failed {
+			health.Errors++
+		}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	health.ErrorRate = float64(health.Errors) / float64(len(h.samples))
+	health.LatencyP50 = percentile(latencies, 50)
+	health.LatencyP95 = percentile(latencies, 95)
+	health.LatencyP99 = percentile(latencies, 99)
+	return health
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// Health returns the latency and failures of recent requests. Adapters
+// reporting their Wi-Fi signal or uptime add them.
+func (b *BaseAppliance) Health(ctx context.Context) (*AdapterHealth, error) {
+	health := b.health.snapshot()
+	return &health, nil
+}
+
+// Close stops exporting the health metrics registered by WithMetrics. The
+// appliance can still be used.
+func (b *BaseAppliance) Close() error {
+	if b.metrics == nil {
+		return nil
+	}
+	err := b.metrics.Unregister()
+	b.metrics = nil
+	return err
+}
+
+// registerHealthMetrics exports the adapter health as gauges, the signal
+// as last read by Health, until Close
+func (b *BaseAppliance) registerHealthMetrics(provider metric.MeterProvider) error {
+	meter := provider.Meter(tracerName)
+
+	requests, err := meter.Int64ObservableGauge("daikin.adapter.requests",
+		metric.WithDescription("Recent requests the adapter health covers"))
+	if err != nil {
+		return err
+	}
+	errorRate, err := meter.Float64ObservableGauge("daikin.adapter.error_rate",
+		metric.WithDescription("Share of recent requests that failed"))
+	if err != nil {
+		return err
+	}
+	latency, err := meter.Float64ObservableGauge("daikin.adapter.latency",
+		metric.WithDescription("Latency percentiles of recent requests"), metric.WithUnit("s"))
+	if err != nil {
+		return err
+	}
+	rssi, err := meter.Int64ObservableGauge("daikin.adapter.rssi",
+		metric.WithDescription("Wi-Fi signal reported by the adapter"), metric.WithUnit("dBm"))
+	if err != nil {
+		return err
+	}
+
+	b.metrics, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		health := b.health.snapshot()
+		device := metric.WithAttributes(attrDeviceIP.String(b.DeviceIP), attrDeviceType.String(b.deviceType))
+
+		o.ObserveInt64(requests, int64(health.Requests), device)
+		o.ObserveFloat64(errorRate, health.ErrorRate, device)
+		for quantile, value := range map[string]time.Duration{
+			"p50": health.LatencyP50,
+			"p95": health.LatencyP95,
+			"p99": health.LatencyP99,
+		} {
+			o.ObserveFloat64(latency, value.Seconds(), device,
+				metric.WithAttributes(attrQuantile.String(quantile)))
+		}
+		if health.RSSI != nil {
+			o.ObserveInt64(rssi, int64(*health.RSSI), device)
+		}
+		return nil
+	}, requests, errorRate, latency, rssi)
+	return err
+}
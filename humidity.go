@@ -0,0 +1,56 @@
+package godaikin
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// HumidityTarget is a target humidity as the devices report it: a
+// percentage such as "50", HumidityAuto or HumidityContinuous
+type HumidityTarget string
+
+const (
+	// HumidityAuto lets the unit choose the humidity, in dry mode
+	HumidityAuto HumidityTarget = "AUTO"
+	// HumidityContinuous dehumidifies without a target, in dry mode
+	HumidityContinuous HumidityTarget = "CONTINUOUS"
+)
+
+// HumidityPercent returns the target for a percentage
+func HumidityPercent(percent int) HumidityTarget {
+	return HumidityTarget(strconv.Itoa(percent))
+}
+
+// Percent returns the target as a percentage, false for HumidityAuto and
+// HumidityContinuous
+func (h HumidityTarget) Percent() (int, bool) {
+	percent, err := strconv.Atoi(string(h))
+	return percent, err == nil
+}
+
+// validate checks the target is a special value or a percentage
+func (h HumidityTarget) validate() error {
+	if h == HumidityAuto || h == HumidityContinuous {
+		return nil
+	}
+	if percent, ok := h.Percent(); ok && percent >= 0 && percent <= 100 {
+		return nil
+	}
+	return fmt.Errorf("invalid target humidity: %s", h)
+}
+
+// HumidityController is implemented by appliances that can read and set
+// the humidity
+type HumidityController interface {
+	SupportsHumidityControl() bool
+	GetHumidity() (float64, error)
+	GetTargetHumidity() (float64, error)
+	GetHumidityTarget() (HumidityTarget, error)
+	SetTargetHumidity(ctx context.Context, target HumidityTarget) error
+}
+
+// isUnsetHumidity reports whether a humidity value means no target
+func isUnsetHumidity(value string) bool {
+	return value == "" || value == "0" || value == "-" || value == "--"
+}
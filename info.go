@@ -0,0 +1,109 @@
+package godaikin
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a firmware version. Adapters report it as "1_2_54" or
+// "1.14.88"; missing parts are zero.
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// ParseVersion parses a firmware version
+func ParseVersion(s string) (Version, error) {
+	parts := strings.FieldsFunc(s, func(r rune) bool { return r == '_' || r == '.' || r == '-' })
+	if len(parts) == 0 || len(parts) > 3 {
+		return Version{}, fmt.Errorf("invalid version %q", s)
+	}
+
+	var numbers [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return Version{}, fmt.Errorf("invalid version %q", s)
+		}
+		numbers[i] = n
+	}
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, nil
+}
+
+// Compare returns -1, 0 or 1 as v is older than, the same as or newer
+// than other
+func (v Version) Compare(other Version) int {
+	for _, diff := range []int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		switch {
+		case diff < 0:
+			return -1
+		case diff > 0:
+			return 1
+		}
+	}
+	return 0
+}
+
+// AtLeast returns whether v is other or newer
+func (v Version) AtLeast(other Version) bool {
+	return v.Compare(other) >= 0
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// DeviceInfo describes the adapter. Fields the adapter does not report are
+// empty.
+type DeviceInfo struct {
+	DeviceType string
+	Name       string
+	MAC        string
+	// Firmware is zero if the version is missing or not understood;
+	// FirmwareString has it as reported
+	Firmware        Version
+	FirmwareString  string
+	Revision        string
+	ProtocolVersion string
+	AdapterKind     int
+	Region          string
+	SSID            string
+}
+
+// InfoReporter is implemented by appliances describing their adapter
+type InfoReporter interface {
+	Info() DeviceInfo
+}
+
+// Renamer is implemented by appliances whose name can be changed
+type Renamer interface {
+	SetName(ctx context.Context, name string) error
+}
+
+// Info returns the adapter information from the last status update
+// (common/basic_info), where the SSID is percent-encoded
+func (b *BaseAppliance) Info() DeviceInfo {
+	values := b.Values.All()
+
+	info := DeviceInfo{
+		DeviceType:      b.deviceType,
+		Name:            values["name"],
+		FirmwareString:  values["ver"],
+		Revision:        values["rev"],
+		ProtocolVersion: values["pv"],
+		Region:          values["reg"],
+		SSID:            decodeValue(values["ssid"]),
+	}
+	if mac, exists := values["mac"]; exists {
+		info.MAC = formatMAC(mac)
+	}
+	if version, err := ParseVersion(info.FirmwareString); err == nil {
+		info.Firmware = version
+	}
+	info.AdapterKind, _ = strconv.Atoi(values["adp_kind"])
+
+	return info
+}
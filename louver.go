@@ -0,0 +1,63 @@
+package godaikin
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// LouverPosition is the state of one louver axis: LouverStopped,
+// LouverSwing, LouverComfort or a fixed position from LouverFixed
+type LouverPosition string
+
+const (
+	// LouverStopped holds the louver where it is
+	LouverStopped LouverPosition = "stopped"
+	// LouverSwing sweeps the louver continuously
+	LouverSwing LouverPosition = "swing"
+	// LouverComfort lets the unit steer the airflow away from occupants
+	LouverComfort LouverPosition = "comfort"
+)
+
+// Fixed louver positions range from 1, the highest or leftmost, to 5
+const (
+	MinLouverPosition = 1
+	MaxLouverPosition = 5
+)
+
+// LouverFixed returns the fixed louver position n
+func LouverFixed(n int) LouverPosition {
+	return LouverPosition(strconv.Itoa(n))
+}
+
+// Fixed returns the fixed position, false for the other states
+func (p LouverPosition) Fixed() (int, bool) {
+	n, err := strconv.Atoi(string(p))
+	return n, err == nil && n >= MinLouverPosition && n <= MaxLouverPosition
+}
+
+func (p LouverPosition) validate() error {
+	switch p {
+	case LouverStopped, LouverSwing, LouverComfort:
+		return nil
+	}
+	if _, ok := p.Fixed(); ok {
+		return nil
+	}
+	return fmt.Errorf("invalid louver position: %s", p)
+}
+
+// LouverSettings are the vertical and horizontal louver states of a mode
+type LouverSettings struct {
+	Vertical   LouverPosition
+	Horizontal LouverPosition
+}
+
+// LouverController is implemented by appliances whose louvers can be set
+// per mode: BRP069, BRP072C and BRP084. Settings a unit cannot apply fail
+// with a CapabilityError.
+type LouverController interface {
+	SupportsLouverControl() bool
+	GetLouverSettings(mode string) (LouverSettings, error)
+	SetLouverSettings(ctx context.Context, mode string, settings LouverSettings) error
+}
@@ -0,0 +1,161 @@
+package godaikin
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// redactedValue replaces secrets in logs, errors and recordings
+const redactedValue = "REDACTED"
+
+// secretParams are query parameters and response fields that carry credentials
+var secretParams = map[string]bool{
+	"pass":     true,
+	"password": true,
+	"key":      true,
+	"uuid":     true,
+}
+
+// secretHeaders are request headers that carry credentials
+var secretHeaders = map[string]bool{
+	"X-Daikin-Uuid": true,
+}
+
+var (
+	// urlPattern matches URLs quoted in error messages
+	urlPattern = regexp.MustCompile(`https?://[^\s"']+`)
+	// secretFieldPattern matches credentials in key=value bodies
+	secretFieldPattern = regexp.MustCompile(`\b(key|pass|password|uuid)=[^,&\s"']*`)
+	// macFieldPattern matches MAC addresses in key=value and BRP084 JSON bodies
+	macFieldPattern = regexp.MustCompile(`(mac=|"pn":"mac","pv":")[0-9A-Fa-f:-]+`)
+	// macPattern matches colon or dash separated MAC addresses
+	macPattern = regexp.MustCompile(`\b[0-9A-Fa-f]{2}([:-][0-9A-Fa-f]{2}){5}\b`)
+	// uuidPattern matches dashed UUIDs
+	uuidPattern = regexp.MustCompile(`\b[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}\b`)
+)
+
+// redactQuery returns the query in canonical order with secrets redacted
+func redactQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return secretFieldPattern.ReplaceAllString(rawQuery, "${1}="+redactedValue)
+	}
+
+	for name := range query {
+		if secretParams[name] {
+			query.Set(name, redactedValue)
+		}
+	}
+
+	return query.Encode()
+}
+
+// redactURL redacts the secret query parameters of a URL
+func redactURL(rawURL string) string {
+	base, rawQuery, found := strings.Cut(rawURL, "?")
+	if !found {
+		return rawURL
+	}
+	return base + "?" + redactQuery(rawQuery)
+}
+
+// redactSecrets removes credentials from URLs and key=value pairs in text
+func redactSecrets(text string) string {
+	text = urlPattern.ReplaceAllStringFunc(text, redactURL)
+	return secretFieldPattern.ReplaceAllString(text, "${1}="+redactedValue)
+}
+
+// redactIdentifiers removes MAC addresses and UUIDs from text
+func redactIdentifiers(text string) string {
+	text = macFieldPattern.ReplaceAllString(text, "${1}"+redactedValue)
+	text = macPattern.ReplaceAllString(text, redactedValue)
+	return uuidPattern.ReplaceAllString(text, redactedValue)
+}
+
+// redactParams returns a copy of params with credential values redacted
+func redactParams(params map[string]string) map[string]string {
+	if params == nil {
+		return nil
+	}
+
+	result := make(map[string]string, len(params))
+	for key, value := range params {
+		if secretParams[key] {
+			value = redactedValue
+		}
+		result[key] = value
+	}
+	return result
+}
+
+// RedactingLogger is a Logger that removes credentials from log arguments
+// before passing them on
+type RedactingLogger struct {
+	logger Logger
+}
+
+// NewRedactingLogger wraps logger so that passwords, keys and UUIDs never
+// reach it
+func NewRedactingLogger(logger Logger) Logger {
+	if logger == nil {
+		return NoOpLogger{}
+	}
+	switch logger.(type) {
+	case NoOpLogger, *RedactingLogger:
+		return logger
+	}
+	return &RedactingLogger{logger: logger}
+}
+
+func (r *RedactingLogger) Debug(msg string, args ...any) {
+	r.logger.Debug(msg, redactArgs(args)...)
+}
+
+func (r *RedactingLogger) Info(msg string, args ...any) {
+	r.logger.Info(msg, redactArgs(args)...)
+}
+
+func (r *RedactingLogger) Warn(msg string, args ...any) {
+	r.logger.Warn(msg, redactArgs(args)...)
+}
+
+func (r *RedactingLogger) Error(msg string, args ...any) {
+	r.logger.Error(msg, redactArgs(args)...)
+}
+
+// redactArgs redacts the values of key/value logging arguments
+func redactArgs(args []any) []any {
+	result := make([]any, len(args))
+	for i, arg := range args {
+		if i%2 == 1 {
+			if key, ok := args[i-1].(string); ok && secretParams[key] {
+				result[i] = redactedValue
+				continue
+			}
+		}
+		result[i] = redactValue(arg)
+	}
+	return result
+}
+
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case string:
+		return redactSecrets(v)
+	case map[string]string:
+		return redactParams(v)
+	case map[string]interface{}, []map[string]interface{}:
+		return redactSecrets(fmt.Sprintf("%v", v))
+	case error:
+		return errors.New(redactSecrets(v.Error()))
+	default:
+		return value
+	}
+}
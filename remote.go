@@ -0,0 +1,47 @@
+package godaikin
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RemoteMethod is how the adapter reaches the Daikin cloud
+type RemoteMethod string
+
+const (
+	// RemoteHomeOnly keeps the adapter local-only
+	RemoteHomeOnly RemoteMethod = "home only"
+	// RemotePolling lets the adapter poll the Daikin cloud
+	RemotePolling RemoteMethod = "polling"
+)
+
+// RemoteSettings is the cloud connection setting of an adapter. Intervals
+// are zero when the adapter does not report them and left unchanged when
+// written as zero.
+type RemoteSettings struct {
+	Method RemoteMethod
+	// NoticeInterval is how often the adapter reports its address
+	NoticeInterval time.Duration
+	// SyncInterval is how often the adapter polls for remote commands
+	SyncInterval time.Duration
+}
+
+func (s RemoteSettings) validate() error {
+	if s.Method != RemoteHomeOnly && s.Method != RemotePolling {
+		return fmt.Errorf("invalid remote method: %s", s.Method)
+	}
+	if s.NoticeInterval < 0 || s.SyncInterval < 0 {
+		return fmt.Errorf("invalid remote intervals: %v, %v", s.NoticeInterval, s.SyncInterval)
+	}
+	return nil
+}
+
+// RemoteController is implemented by appliances whose cloud connection can
+// be turned off
+type RemoteController interface {
+	// SupportsRemoteSettings reports whether the adapter has the setting
+	SupportsRemoteSettings() bool
+	GetRemoteSettings(ctx context.Context) (*RemoteSettings, error)
+	SetRemoteSettings(ctx context.Context, settings RemoteSettings) error
+}
@@ -0,0 +1,100 @@
+package godaikin
+
+import "github.com/jattkaim/godaikin/codec"
+
+// BasicInfo is the typed form of common/basic_info
+type BasicInfo struct {
+	Type            string `daikin:"type"`
+	Region          string `daikin:"reg"`
+	DST             bool   `daikin:"dst"`
+	Version         string `daikin:"ver"`
+	Revision        string `daikin:"rev"`
+	Power           bool   `daikin:"pow"`
+	Error           int    `daikin:"err"`
+	Location        int    `daikin:"location"`
+	Name            string `daikin:"name"`
+	Icon            int    `daikin:"icon"`
+	Method          string `daikin:"method"`
+	Port            int    `daikin:"port"`
+	AdapterKind     int    `daikin:"adp_kind"`
+	ProtocolVersion string `daikin:"pv"`
+	LED             bool   `daikin:"led"`
+	MAC             string `daikin:"mac"`
+	AdapterMode     string `daikin:"adp_mode"`
+	HolidayEnabled  bool   `daikin:"en_hol"`
+	GroupName       string `daikin:"grp_name"`
+	GroupEnabled    bool   `daikin:"en_grp"`
+}
+
+// ControlInfo is the typed form of aircon/get_control_info. The setpoints
+// stay strings because the devices report "M" in dry mode and "--" or
+// "AUTO" in fan mode.
+type ControlInfo struct {
+	Power             bool   `daikin:"pow"`
+	Mode              string `daikin:"mode"`
+	AdvancedMode      string `daikin:"adv,omitempty"`
+	TargetTemperature string `daikin:"stemp"`
+	TargetHumidity    string `daikin:"shum"`
+	FanRate           string `daikin:"f_rate,omitempty"`
+	FanDirection      string `daikin:"f_dir,omitempty"`
+}
+
+// SensorInfo is the typed form of aircon/get_sensor_info. Readings the unit
+// does not have are nil.
+type SensorInfo struct {
+	InsideTemperature   *float64 `daikin:"htemp"`
+	InsideHumidity      *float64 `daikin:"hhum"`
+	OutsideTemperature  *float64 `daikin:"otemp"`
+	Error               int      `daikin:"err"`
+	CompressorFrequency *float64 `daikin:"cmpfreq"`
+}
+
+// ModelInfo is the typed form of aircon/get_model_info
+type ModelInfo struct {
+	Model                 string `daikin:"model"`
+	Type                  string `daikin:"type"`
+	ProtocolVersion       string `daikin:"pv"`
+	ControlVersion        string `daikin:"cpv"`
+	ModelID               string `daikin:"mid"`
+	FanRateSupported      bool   `daikin:"en_frate"`
+	FanDirectionSupported bool   `daikin:"en_fdir"`
+	FanDirectionSteps     int    `daikin:"s_fdir"`
+	ZoneCount             *int   `daikin:"en_zone"`
+}
+
+// ZoneSetting is the typed form of aircon/get_zone_setting
+type ZoneSetting struct {
+	Names []string `daikin:"zone_name"`
+	OnOff []bool   `daikin:"zone_onoff"`
+}
+
+// ValuesDecoder is implemented by devices whose values can be decoded into
+// typed structs, which all drivers in this package are
+type ValuesDecoder interface {
+	DecodeValues(v interface{}) error
+}
+
+// DecodeValues decodes the current values of the device into a struct with
+// `daikin` tags, such as ControlInfo
+func (b *BaseAppliance) DecodeValues(v interface{}) error {
+	if err := codec.UnmarshalValues(b.Values.All(), v); err != nil {
+		return NewParseError("failed to decode values", err)
+	}
+	return nil
+}
+
+// DecodeValues decodes the current values of the device into a struct with
+// `daikin` tags, such as ZoneSetting. Zone lists have their encoded
+// separators restored first.
+func (d *DaikinAirBase) DecodeValues(v interface{}) error {
+	values := d.Values.All()
+	for _, key := range []string{"zone_name", "zone_onoff", "lztemp_c", "lztemp_h"} {
+		if value, exists := values[key]; exists {
+			values[key] = zoneSeparator.Replace(value)
+		}
+	}
+	if err := codec.UnmarshalValues(values, v); err != nil {
+		return NewParseError("failed to decode values", err)
+	}
+	return nil
+}
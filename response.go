@@ -1,45 +1,85 @@
 package godaikin
 
 import (
-	"net/url"
-	"strings"
-)
+	"fmt"
+	"io"
 
-// parseResponse parses a Daikin response string into a map
-// Response format is like: "ret=OK,type=aircon,reg=eu,dst=1,ver=1_2_54"
-func parseResponse(responseBody string) (map[string]string, error) {
-	response := make(map[string]string)
+	"github.com/jattkaim/godaikin/codec"
+)
 
-	pairs := strings.Split(responseBody, ",")
+// DefaultMaxResponseSize is the default limit for response bodies. Even the
+// longest Daikin responses (year power history, zone settings) are a few KiB.
+const DefaultMaxResponseSize = 64 * 1024
 
-	for _, pair := range pairs {
-		parts := strings.SplitN(pair, "=", 2)
-		if len(parts) != 2 {
-			continue
-		}
+// readBody reads the whole body, failing with a ParseError rather than
+// silently truncating when it is longer than maxSize
+func readBody(body io.Reader, maxSize int64) ([]byte, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxResponseSize
+	}
 
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
+	data, err := io.ReadAll(io.LimitReader(body, maxSize+1))
+	if err != nil {
+		return nil, NewConnectionError("failed to read response body", err)
+	}
 
-		response[key] = value
+	if int64(len(data)) > maxSize {
+		return nil, NewParseError(fmt.Sprintf("response truncated: body exceeds %d bytes", maxSize), nil)
 	}
 
+	return data, nil
+}
+
+// parseResponse parses a Daikin response string into a map
+// Response format is like: "ret=OK,type=aircon,reg=eu,dst=1,ver=1_2_54"
+func parseResponse(responseBody string) (map[string]string, error) {
+	response, _, err := decodeResponse(responseBody)
+	return response, err
+}
+
+// decodeResponse parses a Daikin response string into a map and also
+// returns the raw 'ret' code
+func decodeResponse(responseBody string) (map[string]string, string, error) {
+	response := decodeKeyValues(responseBody, ",")
+
 	ret, exists := response["ret"]
 	if !exists {
-		return nil, NewParseError("missing 'ret' field in response", nil)
+		return nil, "", NewParseError("missing 'ret' field in response", nil)
 	}
 
 	if ret != "OK" {
-		return make(map[string]string), nil
+		return make(map[string]string), ret, nil
 	}
 
 	delete(response, "ret")
 
 	if name, exists := response["name"]; exists {
-		if decodedName, err := url.QueryUnescape(name); err == nil {
-			response["name"] = decodedName
-		}
+		response["name"] = decodeValue(name)
 	}
 
-	return response, nil
+	return response, ret, nil
+}
+
+// decodeKeyValues splits a body of key=value pairs joined by separator.
+// Values are kept as sent by the device, still percent-encoded, so they can
+// be sent back unchanged; use decodeValue and decodeList to read them.
+func decodeKeyValues(body, separator string) map[string]string {
+	return codec.Split(body, separator)
+}
+
+// decodeValue percent-decodes a value, see codec.Unescape
+func decodeValue(value string) string {
+	return codec.Unescape(value)
+}
+
+// encodeValue percent-encodes every byte of a value, for query strings
+// built with rawQuery
+func encodeValue(value string) string {
+	return codec.Escape(value)
+}
+
+// decodeList splits a list value on separator, which is ";" for zone fields
+// and "/" for energy histories, and percent-decodes each item
+func decodeList(value, separator string) []string {
+	return codec.SplitList(value, separator)
 }
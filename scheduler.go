@@ -0,0 +1,362 @@
+package godaikin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultCatchUpWindow is how late a missed rule may still run
+const DefaultCatchUpWindow = time.Hour
+
+// SchedulerClock is the time source of a Scheduler
+type SchedulerClock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Action is what a rule does to each of its appliances
+type Action func(ctx context.Context, appliance Appliance) error
+
+// SetAction applies settings with Set
+func SetAction(settings map[string]string) Action {
+	return func(ctx context.Context, appliance Appliance) error {
+		return appliance.Set(ctx, settings)
+	}
+}
+
+// HolidayAction turns holiday mode on or off
+func HolidayAction(mode string) Action {
+	return func(ctx context.Context, appliance Appliance) error {
+		return appliance.SetHoliday(ctx, mode)
+	}
+}
+
+// AdvancedModeAction turns an advanced mode on or off
+func AdvancedModeAction(mode, value string) Action {
+	return func(ctx context.Context, appliance Appliance) error {
+		return appliance.SetAdvancedMode(ctx, mode, value)
+	}
+}
+
+// Rule runs an action at a time of day on some days of the week
+type Rule struct {
+	// Name identifies the rule and must be unique within a scheduler
+	Name string
+	// Days the rule runs on, every day when empty
+	Days   []time.Weekday
+	Hour   int
+	Minute int
+	// Location the time is in, time.Local when nil
+	Location *time.Location
+	Action   Action
+	// Appliances names the registered appliances to act on, all when empty
+	Appliances []string
+}
+
+func (r *Rule) validate() error {
+	if r.Name == "" {
+		return errors.New("rule has no name")
+	}
+	if r.Action == nil {
+		return fmt.Errorf("rule %s has no action", r.Name)
+	}
+	if r.Hour < 0 || r.Hour > 23 || r.Minute < 0 || r.Minute > 59 {
+		return fmt.Errorf("rule %s has invalid time %02d:%02d", r.Name, r.Hour, r.Minute)
+	}
+	for _, day := range r.Days {
+		if day < time.Sunday || day > time.Saturday {
+			return fmt.Errorf("rule %s has invalid day %d", r.Name, day)
+		}
+	}
+	return nil
+}
+
+func (r *Rule) runsOn(day time.Weekday) bool {
+	if len(r.Days) == 0 {
+		return true
+	}
+	for _, d := range r.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) location() *time.Location {
+	if r.Location == nil {
+		return time.Local
+	}
+	return r.Location
+}
+
+// occurrence returns the run time on the day offset days from t
+func (r *Rule) occurrence(t time.Time, offset int) time.Time {
+	local := t.In(r.location())
+	return time.Date(local.Year(), local.Month(), local.Day()+offset, r.Hour, r.Minute, 0, 0, r.location())
+}
+
+// next returns the first run strictly after t
+func (r *Rule) next(t time.Time) time.Time {
+	for offset := 0; offset <= 7; offset++ {
+		run := r.occurrence(t, offset)
+		if run.After(t) && r.runsOn(run.Weekday()) {
+			return run
+		}
+	}
+	return time.Time{}
+}
+
+// previous returns the last run at or before t
+func (r *Rule) previous(t time.Time) time.Time {
+	for offset := 0; offset >= -7; offset-- {
+		run := r.occurrence(t, offset)
+		if !run.After(t) && r.runsOn(run.Weekday()) {
+			return run
+		}
+	}
+	return time.Time{}
+}
+
+type SchedulerOption func(*Scheduler)
+
+// WithSchedulerClock replaces the system clock, for tests
+func WithSchedulerClock(clock SchedulerClock) SchedulerOption {
+	return func(s *Scheduler) {
+		s.clock = clock
+	}
+}
+
+func WithSchedulerLogger(slogger *slog.Logger) SchedulerOption {
+	return func(s *Scheduler) {
+		s.logger = NewRedactingLogger(NewSlogAdapter(slogger))
+	}
+}
+
+// WithCatchUpWindow sets how late a run missed while the scheduler was
+// stopped or blocked may still happen; zero skips missed runs
+func WithCatchUpWindow(window time.Duration) SchedulerOption {
+	return func(s *Scheduler) {
+		s.catchUp = window
+	}
+}
+
+// WithLastRuns restores the last run times saved from LastRuns, so rules
+// that already ran before a restart are not caught up again
+func WithLastRuns(runs map[string]time.Time) SchedulerOption {
+	return func(s *Scheduler) {
+		for name, run := range runs {
+			s.lastRun[name] = run
+		}
+	}
+}
+
+// Scheduler runs weekly rules against registered appliances, for units
+// without on-device schedules
+type Scheduler struct {
+	clock   SchedulerClock
+	logger  Logger
+	catchUp time.Duration
+
+	mu         sync.Mutex
+	appliances map[string]Appliance
+	rules      map[string]*Rule
+	lastRun    map[string]time.Time
+}
+
+func NewScheduler(opts ...SchedulerOption) *Scheduler {
+	s := &Scheduler{
+		clock:      systemClock{},
+		logger:     NoOpLogger{},
+		catchUp:    DefaultCatchUpWindow,
+		appliances: make(map[string]Appliance),
+		rules:      make(map[string]*Rule),
+		lastRun:    make(map[string]time.Time),
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(s)
+		}
+	}
+
+	return s
+}
+
+// Register adds an appliance under a name rules can refer to
+func (s *Scheduler) Register(name string, appliance Appliance) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.appliances[name] = appliance
+}
+
+// Unregister removes an appliance
+func (s *Scheduler) Unregister(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.appliances, name)
+}
+
+// AddRule adds or replaces a rule. Unless its last run is known from
+// WithLastRuns or an earlier rule of that name, runs within the catch-up
+// window before now count as missed and happen on the next RunPending.
+func (s *Scheduler) AddRule(rule Rule) error {
+	if err := rule.validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules[rule.Name] = &rule
+	if _, known := s.lastRun[rule.Name]; !known {
+		s.lastRun[rule.Name] = s.clock.Now().Add(-s.catchUp)
+	}
+	return nil
+}
+
+// RemoveRule removes a rule
+func (s *Scheduler) RemoveRule(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.rules, name)
+	delete(s.lastRun, name)
+}
+
+// LastRuns returns when each rule last ran, or was last skipped, for
+// saving across restarts with WithLastRuns
+func (s *Scheduler) LastRuns() map[string]time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs := make(map[string]time.Time, len(s.lastRun))
+	for name := range s.rules {
+		runs[name] = s.lastRun[name]
+	}
+	return runs
+}
+
+// NextRun returns when a rule runs next
+func (s *Scheduler) NextRun(name string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule, exists := s.rules[name]
+	if !exists {
+		return time.Time{}, fmt.Errorf("rule %s not found", name)
+	}
+	return rule.next(s.clock.Now()), nil
+}
+
+// NextRuns returns when each rule runs next
+func (s *Scheduler) NextRuns() map[string]time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	runs := make(map[string]time.Time, len(s.rules))
+	for name, rule := range s.rules {
+		runs[name] = rule.next(now)
+	}
+	return runs
+}
+
+// RunPending runs every rule due since its last run. A rule that missed
+// several runs runs once; runs older than the catch-up window are skipped.
+func (s *Scheduler) RunPending(ctx context.Context) error {
+	type job struct {
+		rule       *Rule
+		appliances map[string]Appliance
+	}
+
+	s.mu.Lock()
+	now := s.clock.Now()
+	var jobs []job
+	for name, rule := range s.rules {
+		due := rule.previous(now)
+		if !due.After(s.lastRun[name]) {
+			continue
+		}
+		s.lastRun[name] = now
+		if now.Sub(due) > s.catchUp {
+			s.logger.Warn("Skipping missed scheduled run", "rule", name, "due", due)
+			continue
+		}
+		jobs = append(jobs, job{rule: rule, appliances: s.targets(rule)})
+	}
+	s.mu.Unlock()
+
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].rule.Name < jobs[j].rule.Name })
+
+	var errs []error
+	for _, job := range jobs {
+		names := make([]string, 0, len(job.appliances))
+		for name := range job.appliances {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			s.logger.Info("Running scheduled rule", "rule", job.rule.Name, "appliance", name)
+			if err := job.rule.Action(ctx, job.appliances[name]); err != nil {
+				s.logger.Error("Scheduled rule failed", "rule", job.rule.Name, "appliance", name, "error", err)
+				errs = append(errs, fmt.Errorf("rule %s on %s: %w", job.rule.Name, name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// targets returns the registered appliances a rule acts on; the caller
+// holds the lock
+func (s *Scheduler) targets(rule *Rule) map[string]Appliance {
+	targets := make(map[string]Appliance)
+	if len(rule.Appliances) == 0 {
+		for name, appliance := range s.appliances {
+			targets[name] = appliance
+		}
+		return targets
+	}
+
+	for _, name := range rule.Appliances {
+		if appliance, exists := s.appliances[name]; exists {
+			targets[name] = appliance
+		} else {
+			s.logger.Warn("Scheduled rule refers to unknown appliance", "rule", rule.Name, "appliance", name)
+		}
+	}
+	return targets
+}
+
+// Run runs rules as they fall due until ctx is done. Rule failures are
+// logged and do not stop the scheduler.
+func (s *Scheduler) Run(ctx context.Context) error {
+	for {
+		_ = s.RunPending(ctx)
+
+		wait := time.Minute
+		for _, next := range s.NextRuns() {
+			if until := next.Sub(s.clock.Now()); until < wait {
+				wait = until
+			}
+		}
+		if wait < 0 {
+			wait = 0
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.clock.After(wait):
+		}
+	}
+}
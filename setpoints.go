@@ -0,0 +1,25 @@
+package godaikin
+
+import "context"
+
+// ModeSetpoint is the setpoint a unit remembers for a mode and restores
+// when switched to it. Empty fields are unknown when read and left
+// unchanged when written.
+type ModeSetpoint struct {
+	// Temperature is in °C, or "M" or "--" in modes without a setpoint
+	Temperature string
+	// Humidity is only remembered by BRP069 units
+	Humidity HumidityTarget
+	FanRate  string
+}
+
+// SetpointMemory is implemented by appliances exposing the setpoints they
+// remember for each mode
+type SetpointMemory interface {
+	// GetModeSetpoints returns the remembered setpoints keyed by mode:
+	// auto, dry, cool, heat or fan
+	GetModeSetpoints() map[string]ModeSetpoint
+	// SetModeSetpoint changes the remembered setpoint of a mode without
+	// switching the unit into it
+	SetModeSetpoint(ctx context.Context, mode string, setpoint ModeSetpoint) error
+}
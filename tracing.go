@@ -0,0 +1,87 @@
+package godaikin
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// tracerName is the instrumentation scope of spans created by the library
+const tracerName = "github.com/jattkaim/godaikin"
+
+// Span attribute keys
+const (
+	attrDeviceIP     = attribute.Key("daikin.device.ip")
+	attrDeviceType   = attribute.Key("daikin.device.type")
+	attrProbe        = attribute.Key("daikin.probe")
+	attrResourcePath = attribute.Key("daikin.resource.path")
+	attrTargets      = attribute.Key("daikin.multireq.to")
+	attrRet          = attribute.Key("daikin.ret")
+	attrRsc          = attribute.Key("daikin.rsc")
+	attrHTTPStatus   = attribute.Key("http.response.status_code")
+	attrErrorType    = attribute.Key("error.type")
+	attrQuantile     = attribute.Key("daikin.quantile")
+)
+
+// WithTracerProvider enables OpenTelemetry tracing of connections
+func WithTracerProvider(provider trace.TracerProvider) ClientOption {
+	return func(c *DaikinClient) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithTracing enables OpenTelemetry tracing of detection, reads and commands
+func WithTracing(provider trace.TracerProvider) Option {
+	return func(c *Config) {
+		c.TracerProvider = provider
+	}
+}
+
+// newTracer returns a tracer from provider, or a no-op tracer if nil
+func newTracer(provider trace.TracerProvider) trace.Tracer {
+	if provider == nil {
+		provider = noop.NewTracerProvider()
+	}
+	return provider.Tracer(tracerName)
+}
+
+// startSpan starts a span for an appliance operation
+func (b *BaseAppliance) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attrDeviceIP.String(b.DeviceIP), attrDeviceType.String(b.deviceType))
+	return b.Tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan records err, if any, on span and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetAttributes(attrErrorType.String(errorType(err)))
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// errorType classifies err by the library error types
+func errorType(err error) string {
+	var authErr *AuthenticationError
+	var connErr *ConnectionError
+	var parseErr *ParseError
+	var daikinErr *DaikinError
+
+	switch {
+	case errors.As(err, &authErr):
+		return "authentication"
+	case errors.As(err, &connErr):
+		return "connection"
+	case errors.As(err, &parseErr):
+		return "parse"
+	case errors.As(err, &daikinErr):
+		return "daikin"
+	default:
+		return "other"
+	}
+}
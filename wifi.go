@@ -0,0 +1,201 @@
+package godaikin
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/jattkaim/godaikin/codec"
+)
+
+// DefaultAccessPointAddress is the adapter's address on its own network
+// while in access-point mode
+const DefaultAccessPointAddress = "192.168.127.1"
+
+// WifiSecurity is the security of a Wi-Fi network
+type WifiSecurity string
+
+const (
+	WifiOpen WifiSecurity = "none"
+	WifiWEP  WifiSecurity = "wep"
+	WifiWPA  WifiSecurity = "wpa"
+	WifiWPA2 WifiSecurity = "wpa2"
+	// WifiMixed accepts WPA and WPA2
+	WifiMixed WifiSecurity = "mixed"
+)
+
+// WifiSettings is the network an adapter joins (common/get_wifi_setting)
+type WifiSettings struct {
+	SSID     string
+	Security WifiSecurity
+	// Connected is whether the adapter is linked to the network
+	Connected bool
+}
+
+// wifiSetting is the typed form of common/get_wifi_setting
+type wifiSetting struct {
+	SSID     string `daikin:"ssid"`
+	Security string `daikin:"security"`
+	Link     bool   `daikin:"link"`
+	// RSSI is not confirmed by a capture from a real adapter
+	RSSI *int `daikin:"rssi"`
+}
+
+// validateWifi checks the SSID and that the passphrase suits security
+func validateWifi(ssid string, security WifiSecurity, passphrase string) error {
+	if len(ssid) == 0 || len(ssid) > 32 {
+		return fmt.Errorf("invalid SSID %q: must be 1-32 bytes", ssid)
+	}
+
+	switch security {
+	case WifiOpen:
+		if passphrase != "" {
+			return errors.New("open networks take no passphrase")
+		}
+	case WifiWEP:
+		// 5 or 13 characters, or 10 or 26 hex digits
+		switch len(passphrase) {
+		case 5, 13:
+		case 10, 26:
+			if _, err := hex.DecodeString(passphrase); err != nil {
+				return errors.New("invalid WEP key: not hexadecimal")
+			}
+		default:
+			return errors.New("invalid WEP key: must be 5 or 13 characters or 10 or 26 hex digits")
+		}
+	case WifiWPA, WifiWPA2, WifiMixed:
+		// 8 to 63 characters, or a 64 hex digit key
+		switch {
+		case len(passphrase) == 64:
+			if _, err := hex.DecodeString(passphrase); err != nil {
+				return errors.New("invalid WPA key: 64 characters must be hexadecimal")
+			}
+		case len(passphrase) < 8 || len(passphrase) > 63:
+			return errors.New("invalid WPA passphrase: must be 8-63 characters")
+		}
+	default:
+		return fmt.Errorf("invalid Wi-Fi security: %s", security)
+	}
+	return nil
+}
+
+// GetWifiSettings returns the network the adapter joins
+func (d *DaikinBRP069) GetWifiSettings(ctx context.Context) (*WifiSettings, error) {
+	data, err := d.getResource(ctx, "common/get_wifi_setting", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Wi-Fi settings: %w", err)
+	}
+	if _, exists := data["ssid"]; !exists {
+		return nil, NewCapabilityError("Wi-Fi settings not reported by this adapter", nil)
+	}
+
+	var setting wifiSetting
+	if err := codec.UnmarshalValues(data, &setting); err != nil {
+		return nil, NewParseError("failed to decode Wi-Fi settings", err)
+	}
+
+	return &WifiSettings{
+		SSID:      setting.SSID,
+		Security:  WifiSecurity(setting.Security),
+		Connected: setting.Link,
+	}, nil
+}
+
+// Health adds the Wi-Fi signal (rssi in common/get_wifi_setting, where
+// reported) to the figures of recent requests, which leave out this read.
+// The signal is nil if the read fails.
+func (d *DaikinBRP069) Health(ctx context.Context) (*AdapterHealth, error) {
+	health, err := d.BaseAppliance.Health(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rssi *int
+	if data, err := d.getResource(ctx, "common/get_wifi_setting", nil); err == nil {
+		var setting wifiSetting
+		if codec.UnmarshalValues(data, &setting) == nil {
+			rssi = setting.RSSI
+		}
+	}
+	d.health.setRSSI(rssi)
+	health.RSSI = rssi
+	return health, nil
+}
+
+// SetWifiSettings changes the network the adapter joins, taking effect
+// after a reboot
+func (d *DaikinBRP069) SetWifiSettings(ctx context.Context, ssid string, security WifiSecurity, passphrase string) error {
+	if err := validateWifi(ssid, security, passphrase); err != nil {
+		return err
+	}
+
+	params := map[string]string{
+		"ssid":     encodeValue(ssid),
+		"security": string(security),
+		"key":      encodeValue(passphrase),
+	}
+
+	d.Logger.Info("Setting Wi-Fi network", "ssid", ssid, "security", security)
+	if err := d.command(ctx, rawQuery("common/set_wifi_setting", params)); err != nil {
+		return fmt.Errorf("failed to set Wi-Fi settings: %w", err)
+	}
+	return nil
+}
+
+// Provision joins a new BRP069 adapter in access-point mode, reached at
+// apAddr (DefaultAccessPointAddress when empty), to a Wi-Fi network and
+// reboots it onto that network. Pass WithKey, or a WithCredentialProvider
+// supplying a key, for a BRP072C adapter.
+func Provision(ctx context.Context, apAddr, ssid string, security WifiSecurity, passphrase string, options ...Option) error {
+	if err := validateWifi(ssid, security, passphrase); err != nil {
+		return err
+	}
+	if apAddr == "" {
+		apAddr = DefaultAccessPointAddress
+	}
+
+	config := &Config{}
+	for _, opt := range options {
+		if opt != nil {
+			opt(config)
+		}
+	}
+
+	credentials, err := config.credentialProvider().Credentials(ctx)
+	if err != nil {
+		return NewAuthenticationError("failed to get credentials", err)
+	}
+
+	device := NewDaikinBRP069(apAddr, nil)
+	if credentials.Key != "" {
+		brp072c := NewDaikinBRP072C(apAddr, credentials.Key, credentials.UUID, nil)
+		applyConfig(brp072c.BaseAppliance, config)
+		if config.Credentials != nil {
+			brp072c.Credentials = config.credentialProvider()
+		}
+		if err := brp072c.Reauthenticate(ctx); err != nil {
+			return err
+		}
+		device = brp072c.DaikinBRP069
+	} else {
+		applyConfig(device.BaseAppliance, config)
+	}
+
+	if _, err := device.GetWifiSettings(ctx); err != nil {
+		return fmt.Errorf("no adapter in access-point mode at %s: %w", apAddr, err)
+	}
+	if err := device.SetWifiSettings(ctx, ssid, security, passphrase); err != nil {
+		return err
+	}
+
+	settings, err := device.GetWifiSettings(ctx)
+	if err != nil {
+		return err
+	}
+	if settings.SSID != ssid || settings.Security != security {
+		return NewDaikinError(fmt.Sprintf("adapter kept network %q (%s)", settings.SSID, settings.Security), nil)
+	}
+
+	return device.Reboot(ctx)
+}